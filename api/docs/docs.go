@@ -15,86 +15,177 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/batch-import/products": {
-            "post": {
-                "consumes": [
-                    "application/json"
+        "/audit": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Newest first by default; sortable by id, changed_at. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "batch-import"
+                    "audit"
+                ],
+                "summary": "Browse the audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "table name, e.g. orders",
+                        "name": "table",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "record id",
+                        "name": "record_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "INSERT, UPDATE or DELETE",
+                        "name": "operation",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "employee id that made the change",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "request id",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "changed from (timestamp)",
+                        "name": "changed_at[gte]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "changed until (timestamp)",
+                        "name": "changed_at[lte]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort field, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, up to 200",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Batch import products from JSON array or CSV (name,unit,cost_price,is_available)",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.AuditEntry"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "integer"
+                                "type": "string"
                             }
                         }
                     }
                 }
             }
         },
-        "/customers": {
+        "/audit/{table}/{id}": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "customers"
+                    "audit"
+                ],
+                "summary": "Field-level change history of a record",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "audited table, e.g. customers",
+                        "name": "table",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "record id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List customers",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Customer"
+                                "$ref": "#/definitions/domain.RecordVersion"
                             }
                         }
-                    }
-                }
-            },
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "customers"
-                ],
-                "summary": "Create customer",
-                "parameters": [
-                    {
-                        "description": "customer",
-                        "name": "customer",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.Customer"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/domain.Customer"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/customers/{id}": {
-            "put": {
+        "/audit/{table}/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restores a customer, dish, recipe line, product or reservation to the state recorded by audit_id, or un-deletes it from its last DELETE snapshot when audit_id is omitted. Reservations, recipe lines and order links removed by the same request are restored too. Live reservations only change status along the status machine. Requires audit:restore.",
                 "consumes": [
                     "application/json"
                 ],
@@ -102,24 +193,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "customers"
+                    "audit"
                 ],
-                "summary": "Update customer",
+                "summary": "Restore a record from the audit log",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customers, dishes, dish_ingredients, products or reservations",
+                        "name": "table",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
-                        "description": "customer id",
+                        "description": "record id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "customer",
-                        "name": "customer",
+                        "description": "audit version",
+                        "name": "restore",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Customer"
+                            "$ref": "#/definitions/handlers.restoreRequest"
                         }
                     }
                 ],
@@ -127,62 +224,84 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Customer"
+                            "$ref": "#/definitions/domain.RestoreResult"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            },
-            "delete": {
-                "tags": [
-                    "customers"
-                ],
-                "summary": "Delete customer",
-                "parameters": [
+            }
+        },
+        "/auth/credentials": {
+            "put": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "customer id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "BearerAuth": []
                     }
                 ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
-                    }
-                }
-            }
-        },
-        "/dishes": {
-            "get": {
-                "produces": [
+                "description": "Requires current_pin or current_password. PIN is 4 to 8 digits, password at least 8 characters; an omitted field is kept.",
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "dishes"
+                    "auth"
                 ],
-                "summary": "List dishes",
+                "summary": "Change own PIN or password",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "limit",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "current and new credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ownCredentialsRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.Dish"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/login": {
             "post": {
+                "description": "Send employee_id and pin (POS) or login and password (back office). Pass the token as \"Authorization: Bearer \u003ctoken\u003e\".",
                 "consumes": [
                     "application/json"
                 ],
@@ -190,17 +309,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "dishes"
+                    "auth"
                 ],
-                "summary": "Create or update dish",
+                "summary": "Log in with PIN or password",
                 "parameters": [
                     {
-                        "description": "dish",
-                        "name": "dish",
+                        "description": "credentials",
+                        "name": "credentials",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Dish"
+                            "$ref": "#/definitions/handlers.loginRequest"
                         }
                     }
                 ],
@@ -208,56 +327,72 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Dish"
+                            "$ref": "#/definitions/handlers.loginResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/dishes/{id}": {
-            "delete": {
-                "tags": [
-                    "dishes"
-                ],
-                "summary": "Delete dish",
-                "parameters": [
+        "/auth/me": {
+            "get": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "dish id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "BearerAuth": []
                     }
                 ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
-                    }
-                }
-            }
-        },
-        "/employees": {
-            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "employees"
+                    "auth"
                 ],
-                "summary": "List employees",
+                "summary": "Current employee and their role",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.Employee"
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
-            },
+            }
+        },
+        "/batch-import/customers": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rows are matched by phone number in any format. vip_level is ignored: the loyalty engine sets it from payments.",
                 "consumes": [
                     "application/json"
                 ],
@@ -265,32 +400,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "employees"
+                    "batch-import"
                 ],
-                "summary": "Create employee",
+                "summary": "Batch import customers from JSON array or CSV (full_name,phone,email)",
                 "parameters": [
                     {
-                        "description": "employee",
-                        "name": "employee",
+                        "description": "customers",
+                        "name": "customers",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Employee"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Customer"
+                            }
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Employee"
+                            "$ref": "#/definitions/domain.ImportResult"
                         }
                     }
                 }
             }
         },
-        "/employees/{id}": {
-            "put": {
+        "/batch-import/dish-ingredients": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Dishes are found by name within the category (which may be empty when the dish name is unique), products by name.",
                 "consumes": [
                     "application/json"
                 ],
@@ -298,24 +441,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "employees"
+                    "batch-import"
                 ],
-                "summary": "Update employee",
+                "summary": "Batch import recipes from JSON array or CSV (category,dish,product,quantity)",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "employee id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "employee",
-                        "name": "employee",
+                        "description": "recipe lines",
+                        "name": "ingredients",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Employee"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.DishIngredientImport"
+                            }
                         }
                     }
                 ],
@@ -323,54 +461,143 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Employee"
+                            "$ref": "#/definitions/domain.ImportResult"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/batch-import/dishes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rows are matched by category name and dish name.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "employees"
+                    "batch-import"
                 ],
-                "summary": "Delete employee",
+                "summary": "Batch import dishes from JSON array or CSV (category,name,price,cook_time_minutes,is_active,description)",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "employee id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "dishes",
+                        "name": "dishes",
+                        "in": "body",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.DishImport"
+                            }
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ImportResult"
+                        }
                     }
                 }
             }
         },
-        "/menu-categories": {
-            "get": {
+        "/batch-import/employees": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rows are matched by phone, roles by name. Requires employees:create.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "menu-categories"
+                    "batch-import"
+                ],
+                "summary": "Batch import employees from JSON array or CSV (full_name,phone,email,role,hired_at,is_active)",
+                "parameters": [
+                    {
+                        "description": "employees",
+                        "name": "employees",
+                        "in": "body",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.EmployeeImport"
+                            }
+                        }
+                    }
                 ],
-                "summary": "List menu categories",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ImportResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/batch-import/menu-categories": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rows are matched by name.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "batch-import"
+                ],
+                "summary": "Batch import menu categories from JSON array or CSV (name,description,sort_order,is_active)",
+                "parameters": [
+                    {
+                        "description": "menu categories",
+                        "name": "categories",
+                        "in": "body",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.MenuCategory"
+                                "$ref": "#/definitions/domain.MenuCategoryImport"
                             }
                         }
                     }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ImportResult"
+                        }
+                    }
                 }
-            },
+            }
+        },
+        "/batch-import/products": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Each row is imported behind its own savepoint: failed rows are logged to import_errors and the rest are committed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -378,17 +605,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "menu-categories"
+                    "batch-import"
                 ],
-                "summary": "Create or update menu category",
+                "summary": "Batch import products from JSON array or CSV (name,unit,cost_price,is_available)",
                 "parameters": [
                     {
-                        "description": "category",
-                        "name": "category",
+                        "description": "products",
+                        "name": "products",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.MenuCategory"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Product"
+                            }
                         }
                     }
                 ],
@@ -396,53 +625,114 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.MenuCategory"
+                            "$ref": "#/definitions/domain.ImportResult"
                         }
                     }
                 }
             }
         },
-        "/menu-categories/{id}": {
-            "delete": {
+        "/batch-import/tables": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rows are matched by table_number.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "menu-categories"
+                    "batch-import"
                 ],
-                "summary": "Delete menu category",
+                "summary": "Batch import restaurant tables from JSON array or CSV (table_number,seats,is_active,description)",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "category id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "tables",
+                        "name": "tables",
+                        "in": "body",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.TableImport"
+                            }
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ImportResult"
+                        }
                     }
                 }
             }
         },
-        "/orders": {
+        "/customers": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Newest first by default; sortable by id, full_name, vip_level, created_at. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "customers"
                 ],
-                "summary": "List orders",
+                "summary": "Search customers",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "status filter",
-                        "name": "status",
+                        "description": "phone prefix in any format, e.g. +7 (999) 123 or 8999123",
+                        "name": "phone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "case-insensitive name substring",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "email",
+                        "name": "email",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "limit",
+                        "description": "VIP level",
+                        "name": "vip_level",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created from",
+                        "name": "created_at[gte]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort field, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, up to 200",
                         "name": "limit",
                         "in": "query"
                     }
@@ -451,15 +741,41 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.Order"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.Customer"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             },
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -467,17 +783,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "customers"
                 ],
-                "summary": "Create order with items",
+                "summary": "Create customer",
                 "parameters": [
                     {
-                        "description": "order",
-                        "name": "order",
+                        "description": "customer",
+                        "name": "customer",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.orderRequest"
+                            "$ref": "#/definitions/domain.Customer"
                         }
                     }
                 ],
@@ -485,25 +801,30 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.Order"
+                            "$ref": "#/definitions/domain.Customer"
                         }
                     }
                 }
             }
         },
-        "/orders/{id}/items": {
+        "/customers/{id}": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "customers"
                 ],
-                "summary": "List items for order",
+                "summary": "Get customer profile with loyalty, visits, reservations, orders and favourite dishes",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "order id",
+                        "description": "customer id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -513,15 +834,26 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.OrderItem"
+                            "$ref": "#/definitions/domain.CustomerDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             },
-            "post": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -529,54 +861,53 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "customers"
                 ],
-                "summary": "Add or update order item",
+                "summary": "Update customer",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "order id",
+                        "description": "customer id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "item",
-                        "name": "item",
+                        "description": "customer",
+                        "name": "customer",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.OrderItem"
+                            "$ref": "#/definitions/domain.Customer"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Customer"
+                        }
                     }
                 }
-            }
-        },
-        "/orders/{id}/items/{itemId}": {
+            },
             "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "tags": [
-                    "orders"
+                    "customers"
                 ],
-                "summary": "Delete order item",
+                "summary": "Delete customer",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "order id",
+                        "description": "customer id",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "item id",
-                        "name": "itemId",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
@@ -586,37 +917,98 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/{id}/status": {
-            "put": {
+        "/dishes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sortable by id, name, price. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "orders"
+                    "dishes"
                 ],
-                "summary": "Update order status",
+                "summary": "List dishes",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "order id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "category id",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "active flag",
+                        "name": "is_active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum price",
+                        "name": "price[lte]",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "new status",
-                        "name": "status",
-                        "in": "query",
-                        "required": true
+                        "description": "sort field, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, up to 200",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.Dish"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
-            }
-        },
-        "/payments": {
+            },
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -624,17 +1016,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "payments"
+                    "dishes"
                 ],
-                "summary": "Create or update payment",
+                "summary": "Create or update dish",
                 "parameters": [
                     {
-                        "description": "payment",
-                        "name": "payment",
+                        "description": "dish",
+                        "name": "dish",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Payment"
+                            "$ref": "#/definitions/domain.Dish"
                         }
                     }
                 ],
@@ -642,23 +1034,28 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Payment"
+                            "$ref": "#/definitions/domain.Dish"
                         }
                     }
                 }
             }
         },
-        "/payments/{orderId}": {
+        "/dishes/{id}": {
             "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "tags": [
-                    "payments"
+                    "dishes"
                 ],
-                "summary": "Delete payment by order id",
+                "summary": "Delete dish",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "order id",
-                        "name": "orderId",
+                        "description": "dish id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -670,21 +1067,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/products": {
+        "/dishes/{id}/ingredients": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "dishes"
                 ],
-                "summary": "List products",
+                "summary": "List dish recipe",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "limit",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "dish id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -693,13 +1096,18 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Product"
+                                "$ref": "#/definitions/domain.DishIngredient"
                             }
                         }
                     }
                 }
             },
-            "post": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -707,17 +1115,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "dishes"
                 ],
-                "summary": "Create or update product",
+                "summary": "Replace the whole dish recipe in one transaction",
                 "parameters": [
                     {
-                        "description": "product",
-                        "name": "product",
+                        "type": "integer",
+                        "description": "dish id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new recipe",
+                        "name": "ingredients",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Product"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.DishIngredient"
+                            }
                         }
                     }
                 ],
@@ -725,114 +1143,204 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Product"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.DishIngredient"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/products/{id}": {
-            "delete": {
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "products"
+                    "dishes"
                 ],
-                "summary": "Delete product",
+                "summary": "Add or update ingredient in dish recipe",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "product id",
+                        "description": "dish id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "ingredient",
+                        "name": "ingredient",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.DishIngredient"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.DishIngredient"
+                        }
                     }
                 }
             }
         },
-        "/reports/dishes-availability": {
-            "get": {
-                "produces": [
+        "/dishes/{id}/ingredients/{productId}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "reports"
+                    "dishes"
                 ],
-                "summary": "Dishes availability",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                "summary": "Update ingredient quantity in dish recipe",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "dish id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "quantity",
+                        "name": "ingredient",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.DishAvailability"
-                            }
+                            "$ref": "#/definitions/handlers.ingredientQuantityRequest"
                         }
                     }
-                }
-            }
-        },
-        "/reports/shift-revenue": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "reports"
                 ],
-                "summary": "Shift revenue view",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.ShiftRevenue"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
-            }
-        },
-        "/reports/waiters": {
-            "get": {
-                "produces": [
-                    "application/json"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
                 "tags": [
-                    "reports"
+                    "dishes"
                 ],
-                "summary": "Waiter performance",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.WaiterPerformance"
-                            }
-                        }
+                "summary": "Remove ingredient from dish recipe",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "dish id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
                     }
                 }
             }
         },
-        "/reservations": {
+        "/employees": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sortable by id, full_name, hired_at. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "reservations"
+                    "employees"
                 ],
-                "summary": "List reservations",
+                "summary": "List employees",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "role id",
+                        "name": "role_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "active flag",
+                        "name": "is_active",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "status filter",
-                        "name": "status",
+                        "description": "hired from (date)",
+                        "name": "hired_at[gte]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort field, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, up to 200",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
@@ -840,15 +1348,41 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.Reservation"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.Employee"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             },
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -856,17 +1390,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "reservations"
+                    "employees"
                 ],
-                "summary": "Create reservation",
+                "summary": "Create employee",
                 "parameters": [
                     {
-                        "description": "reservation",
-                        "name": "reservation",
+                        "description": "employee",
+                        "name": "employee",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Reservation"
+                            "$ref": "#/definitions/domain.Employee"
                         }
                     }
                 ],
@@ -874,22 +1408,70 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.Reservation"
+                            "$ref": "#/definitions/domain.Employee"
                         }
                     }
                 }
             }
         },
-        "/reservations/{id}": {
+        "/employees/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Update employee",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "employee id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "employee",
+                        "name": "employee",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Employee"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Employee"
+                        }
+                    }
+                }
+            },
             "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "tags": [
-                    "reservations"
+                    "employees"
                 ],
-                "summary": "Delete reservation",
+                "summary": "Delete employee",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "reservation id",
+                        "description": "employee id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -902,57 +1484,156 @@ const docTemplate = `{
                 }
             }
         },
-        "/reservations/{id}/status": {
+        "/employees/{id}/credentials": {
             "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Requires employees:update. PIN is 4 to 8 digits, password at least 8 characters; an omitted field is kept.",
+                "consumes": [
+                    "application/json"
+                ],
                 "tags": [
-                    "reservations"
+                    "employees"
                 ],
-                "summary": "Update reservation status",
+                "summary": "Set employee PIN or password",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "reservation id",
+                        "description": "employee id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "new status",
-                        "name": "status",
-                        "in": "query",
-                        "required": true
+                        "description": "new credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.credentialsRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK"
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/tables": {
+        "/import-errors": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Newest first by default; sortable by id, created_at. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tables"
+                    "batch-import"
+                ],
+                "summary": "List failed import rows",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "entity, e.g. product",
+                        "name": "entity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "open, resolved or dismissed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created from (timestamp)",
+                        "name": "created_at[gte]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created until (timestamp)",
+                        "name": "created_at[lte]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort field, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, up to 200",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List restaurant tables",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.RestaurantTable"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.ImportError"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
-            },
+            }
+        },
+        "/import-errors/dismiss": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Dismisses the open rows listed in ids, or, without ids, every open row of entity and/or created before created_before.",
                 "consumes": [
                     "application/json"
                 ],
@@ -960,17 +1641,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tables"
+                    "batch-import"
                 ],
-                "summary": "Create or update restaurant table",
+                "summary": "Dismiss failed import rows in bulk",
                 "parameters": [
                     {
-                        "description": "table",
-                        "name": "table",
+                        "description": "rows to dismiss",
+                        "name": "dismiss",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.RestaurantTable"
+                            "$ref": "#/definitions/handlers.dismissImportRequest"
                         }
                     }
                 ],
@@ -978,93 +1659,3254 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.RestaurantTable"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/tables/{id}": {
-            "delete": {
+        "/import-errors/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Re-runs the row through its import path, optionally with corrected raw_data. On success the row is marked resolved; on failure it keeps the new data and error and 422 is returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "tables"
+                    "batch-import"
                 ],
-                "summary": "Delete restaurant table",
+                "summary": "Retry a failed import row",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "table id",
+                        "description": "import error id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "corrected row",
+                        "name": "retry",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.retryImportRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    }
-                }
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ImportError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/menu-categories": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu-categories"
+                ],
+                "summary": "List menu categories",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.MenuCategory"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu-categories"
+                ],
+                "summary": "Create or update menu category",
+                "parameters": [
+                    {
+                        "description": "category",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.MenuCategory"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MenuCategory"
+                        }
+                    }
+                }
+            }
+        },
+        "/menu-categories/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "menu-categories"
+                ],
+                "summary": "Delete menu category",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "category id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/orders": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Newest first by default; sortable by id, created_at. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "List orders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma-separated statuses",
+                        "name": "status[in]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "waiter id",
+                        "name": "waiter_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "shift id",
+                        "name": "shift_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created from",
+                        "name": "created_at[gte]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created until",
+                        "name": "created_at[lte]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort field, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, up to 200",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.Order"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Without shift_id the order joins the open shift; a given shift_id must be an open shift (409 otherwise).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Create order with items",
+                "parameters": [
+                    {
+                        "description": "order",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.orderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Order"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Get order with pricing, items and status history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.OrderDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Includes removed items and edited or deleted payments. Requires audit:read.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Change history of order, its items and payments",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.RecordVersion"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/items": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "List items for order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.OrderItem"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "price_at_moment and discount_percent are ignored unless price_override_reason is set; the caller must then hold orders:override_price and is recorded as price_override_by.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Add or update order item priced from the menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "item",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.OrderItem"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.orderItemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/items/{itemId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Delete order item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "item id",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/payments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Order total, payments and outstanding amount",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.OrderBalance"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/pricing": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The caller must hold orders:discount; omitted service_charge_percent keeps the current rate. An order whose payments cover the new total is closed, with stock warnings as for a payment.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Set order discount and service charge",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "pricing",
+                        "name": "pricing",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.orderPricingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.orderPricingResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/split": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "mode=even splits into parts, mode=items charges each group for its order items, mode=custom uses explicit amounts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Split the outstanding bill into pending payments",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "split",
+                        "name": "split",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.BillSplit"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Payment"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Allowed transitions: new -\u003e in_progress -\u003e closed, new/in_progress -\u003e cancelled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Update order status, deducting or restoring ingredient stock",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "new status",
+                        "name": "status",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payments": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An order may have several payments; it is closed automatically once paid payments cover its total.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Add payment to order",
+                "parameters": [
+                    {
+                        "description": "payment",
+                        "name": "payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Payment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Paid payments cannot be deleted; refund them instead.",
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Delete pending payment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "payment id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Requires audit:read.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Change history of payment and its refunds",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "payment id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.RecordVersion"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/{id}/pay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Mark pending payment as paid",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "payment id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "optional method change",
+                        "name": "payment",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.payPaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/{id}/refunds": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "List refunds of payment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "payment id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Refund"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Omit amount to refund whatever is left on the payment. The caller is recorded as approved_by.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Refund payment partially or in full",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "payment id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "refund",
+                        "name": "refund",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.refundRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Refund"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sortable by id, name. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "List products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "unit",
+                        "name": "unit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "availability",
+                        "name": "is_available",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort field, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, up to 200",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.Product"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Products are matched by name. is_available only applies to products without stock; once stock is recorded it follows the quantity.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Create or update product",
+                "parameters": [
+                    {
+                        "description": "product",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Product"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Product"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Products with stock movements or recipe lines cannot be deleted.",
+                "tags": [
+                    "products"
+                ],
+                "summary": "Delete product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/dishes-availability": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Dishes availability",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.DishAvailability"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/shift-revenue": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Shift revenue view",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ShiftRevenue"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/waiters": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Waiter performance",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.WaiterPerformance"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Latest first by default; sortable by id, reserved_from, reserved_to, created_at. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "List reservations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "table id",
+                        "name": "table_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "customer id",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "starting from",
+                        "name": "reserved_from[gte]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "starting until",
+                        "name": "reserved_from[lte]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort field, prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, up to 200",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.Reservation"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Create reservation",
+                "parameters": [
+                    {
+                        "description": "reservation",
+                        "name": "reservation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Reservation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Reservation"
+                        }
+                    },
+                    "409": {
+                        "description": "overlap with conflicting_reservation",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Delete reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "reservation id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/reservations/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Allowed transitions: new -\u003e confirmed/cancelled, confirmed -\u003e completed/cancelled/no_show.",
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Update reservation status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "reservation id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "new status",
+                        "name": "status",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "List roles with their permissions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Role"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Create role",
+                "parameters": [
+                    {
+                        "description": "role",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Besides these, a role may be granted \"*\" or \"\u003cresource\u003e:*\".",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "List permissions that can be granted",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Get role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the name, description and permissions. System roles cannot be changed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Update role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "role",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "System roles and roles still assigned to employees cannot be deleted.",
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Delete role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/shifts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shifts"
+                ],
+                "summary": "List shifts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Shift"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/shifts/current": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shifts"
+                ],
+                "summary": "Get currently open shift",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Shift"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/shifts/open": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The caller is recorded as opened_by.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shifts"
+                ],
+                "summary": "Open a new shift",
+                "parameters": [
+                    {
+                        "description": "shift",
+                        "name": "shift",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.openShiftRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Shift"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/shifts/{id}/close": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The caller is recorded as closed_by.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shifts"
+                ],
+                "summary": "Close shift with counted revenue",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "shift id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "closing data",
+                        "name": "shift",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.closeShiftRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Shift"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/stock": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "List current stock for all products",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ProductStock"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/stock/adjustments": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Record inventory count adjustment",
+                "parameters": [
+                    {
+                        "description": "counted quantity",
+                        "name": "adjustment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.stockAdjustmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.StockMovement"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock/discrepancies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Products whose stock does not match the movement ledger",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.StockDiscrepancy"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/stock/receipts": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Record goods receipt",
+                "parameters": [
+                    {
+                        "description": "received quantity",
+                        "name": "receipt",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.stockMovementRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.StockMovement"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock/write-offs": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Record stock write-off",
+                "parameters": [
+                    {
+                        "description": "written-off quantity and reason",
+                        "name": "writeOff",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.stockMovementRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.StockMovement"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/stock/{productId}/movements": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Stock movement history for product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.StockMovement"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tables": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tables"
+                ],
+                "summary": "List restaurant tables",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.RestaurantTable"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tables"
+                ],
+                "summary": "Create or update restaurant table",
+                "parameters": [
+                    {
+                        "description": "table",
+                        "name": "table",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.RestaurantTable"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.RestaurantTable"
+                        }
+                    }
+                }
+            }
+        },
+        "/tables/availability": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tables"
+                ],
+                "summary": "Free tables for a time window, best seat fit first",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "window start (RFC3339)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "window end (RFC3339)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of guests",
+                        "name": "guests",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.RestaurantTable"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tables/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "tables"
+                ],
+                "summary": "Delete restaurant table",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "table id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "changed_at": {
+                    "type": "string"
+                },
+                "changed_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "new_data": {
+                    "type": "object"
+                },
+                "old_data": {
+                    "type": "object"
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "record_id": {
+                    "type": "integer"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "table": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BillSplit": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BillSplitGroup"
+                    }
+                },
+                "method": {
+                    "type": "string"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "parts": {
+                    "type": "integer"
+                },
+                "shares": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BillSplitShare"
+                    }
+                }
+            }
+        },
+        "domain.BillSplitGroup": {
+            "type": "object",
+            "properties": {
+                "item_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "method": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BillSplitShare": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "method": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Customer": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "vip_level": {
+                    "description": "VIPLevel is set by the loyalty engine from the customer's spend; create,\nupdate and import ignore it.",
+                    "type": "integer",
+                    "readOnly": true
+                }
+            }
+        },
+        "domain.CustomerDetails": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "favourite_dishes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FavouriteDish"
+                    }
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_visit_at": {
+                    "type": "string"
+                },
+                "loyalty": {
+                    "$ref": "#/definitions/domain.CustomerLoyalty"
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CustomerOrder"
+                    }
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "reservations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Reservation"
+                    }
+                },
+                "vip_level": {
+                    "description": "VIPLevel is set by the loyalty engine from the customer's spend; create,\nupdate and import ignore it.",
+                    "type": "integer",
+                    "readOnly": true
+                },
+                "visits_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.CustomerLoyalty": {
+            "type": "object",
+            "properties": {
+                "discount_percent": {
+                    "type": "number"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "next_level": {
+                    "type": "integer"
+                },
+                "next_level_spend": {
+                    "type": "number"
+                },
+                "spend_to_next_level": {
+                    "type": "number"
+                },
+                "total_spent": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.CustomerOrder": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "discount_percent": {
+                    "description": "DiscountPercent applies to the whole order after line discounts;\nLoyaltyDiscountPercent comes from the customer's VIP level and\nServiceChargePercent from the configuration, both fixed at creation.",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "loyalty_discount_percent": {
+                    "type": "number"
+                },
+                "reservation_id": {
+                    "type": "integer"
+                },
+                "service_charge_percent": {
+                    "type": "number"
+                },
+                "shift_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "table_id": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "number"
+                },
+                "waiter_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Dish": {
+            "type": "object",
+            "properties": {
+                "category_id": {
+                    "type": "integer"
+                },
+                "cook_time_minutes": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.DishAvailability": {
+            "type": "object",
+            "properties": {
+                "all_products_available": {
+                    "type": "boolean"
+                },
+                "can_be_ordered": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.DishImport": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "cook_time_minutes": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.DishIngredient": {
+            "type": "object",
+            "properties": {
+                "dish_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.DishIngredientImport": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "dish": {
+                    "type": "string"
+                },
+                "product": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Employee": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "hired_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "role_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.EmployeeImport": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "hired_at": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FavouriteDish": {
+            "type": "object",
+            "properties": {
+                "dish_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "orders_count": {
+                    "type": "integer"
+                },
+                "portions": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.FieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "new": {
+                    "type": "object"
+                },
+                "old": {
+                    "type": "object"
+                }
+            }
+        },
+        "domain.ImportError": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "entity": {
+                    "type": "string"
+                },
+                "error_message": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "raw_data": {
+                    "type": "object"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "resolved_by": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ImportResult": {
+            "type": "object",
+            "properties": {
+                "error_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "inserted": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.MenuCategory": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sort_order": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.MenuCategoryImport": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sort_order": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Order": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "discount_percent": {
+                    "description": "DiscountPercent applies to the whole order after line discounts;\nLoyaltyDiscountPercent comes from the customer's VIP level and\nServiceChargePercent from the configuration, both fixed at creation.",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "loyalty_discount_percent": {
+                    "type": "number"
+                },
+                "reservation_id": {
+                    "type": "integer"
+                },
+                "service_charge_percent": {
+                    "type": "number"
+                },
+                "shift_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "table_id": {
+                    "type": "integer"
+                },
+                "waiter_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.OrderBalance": {
+            "type": "object",
+            "properties": {
+                "order_id": {
+                    "type": "integer"
+                },
+                "outstanding": {
+                    "type": "number"
+                },
+                "paid": {
+                    "type": "number"
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Payment"
+                    }
+                },
+                "pending": {
+                    "type": "number"
+                },
+                "refunded": {
+                    "type": "number"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.OrderDetails": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "discount_percent": {
+                    "description": "DiscountPercent applies to the whole order after line discounts;\nLoyaltyDiscountPercent comes from the customer's VIP level and\nServiceChargePercent from the configuration, both fixed at creation.",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.OrderItem"
+                    }
+                },
+                "loyalty_discount_percent": {
+                    "type": "number"
+                },
+                "pricing": {
+                    "$ref": "#/definitions/domain.OrderPricing"
+                },
+                "reservation_id": {
+                    "type": "integer"
+                },
+                "service_charge_percent": {
+                    "type": "number"
+                },
+                "shift_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "status_history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.OrderStatusChange"
+                    }
+                },
+                "table_id": {
+                    "type": "integer"
+                },
+                "waiter_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.OrderItem": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "discount_percent": {
+                    "type": "number"
+                },
+                "dish_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "price_at_moment": {
+                    "type": "number"
+                },
+                "price_override_by": {
+                    "description": "PriceOverrideReason marks an approved price and line discount (e.g. a\ncomp); without it PriceAtMoment is taken from the menu and no discount\nis applied. PriceOverrideBy is the approving employee, always the caller.",
+                    "type": "integer"
+                },
+                "price_override_reason": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
             }
-        }
-    },
-    "definitions": {
-        "domain.Customer": {
+        },
+        "domain.OrderPricing": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "line_discounts": {
+                    "type": "number"
+                },
+                "loyalty_discount": {
+                    "type": "number"
+                },
+                "loyalty_discount_percent": {
+                    "type": "number"
+                },
+                "order_discount": {
+                    "type": "number"
+                },
+                "order_discount_percent": {
+                    "type": "number"
+                },
+                "service_charge": {
+                    "type": "number"
+                },
+                "service_charge_percent": {
+                    "type": "number"
+                },
+                "subtotal": {
+                    "type": "number"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.OrderStatusChange": {
+            "type": "object",
+            "properties": {
+                "changed_at": {
                     "type": "string"
                 },
-                "email": {
+                "from_status": {
                     "type": "string"
                 },
-                "full_name": {
+                "id": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "to_status": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Page": {
+            "type": "object",
+            "properties": {
+                "items": {},
+                "next_cursor": {
                     "type": "string"
+                }
+            }
+        },
+        "domain.Payment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "phone": {
+                "method": {
                     "type": "string"
                 },
-                "vip_level": {
+                "order_id": {
                     "type": "integer"
+                },
+                "paid_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
                 }
             }
         },
-        "domain.Dish": {
+        "domain.Product": {
             "type": "object",
             "properties": {
-                "category_id": {
+                "cost_price": {
+                    "type": "number"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "cook_time_minutes": {
+                "is_available": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ProductStock": {
+            "type": "object",
+            "properties": {
+                "product_id": {
                     "type": "integer"
                 },
-                "description": {
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.RecordRef": {
+            "type": "object",
+            "properties": {
+                "action": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "is_active": {
-                    "type": "boolean"
+                "table": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.RecordVersion": {
+            "type": "object",
+            "properties": {
+                "audit_id": {
+                    "type": "integer"
                 },
-                "name": {
+                "changed_at": {
                     "type": "string"
                 },
-                "price": {
+                "changed_by": {
+                    "type": "string"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldChange"
+                    }
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "record_id": {
+                    "type": "integer"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "table": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Refund": {
+            "type": "object",
+            "properties": {
+                "amount": {
                     "type": "number"
+                },
+                "approved_by": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "payment_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
                 }
             }
         },
-        "domain.DishAvailability": {
+        "domain.Reservation": {
             "type": "object",
             "properties": {
-                "all_products_available": {
-                    "type": "boolean"
+                "created_at": {
+                    "type": "string"
                 },
-                "can_be_ordered": {
-                    "type": "boolean"
+                "customer_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reserved_from": {
+                    "type": "string"
+                },
+                "reserved_to": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "table_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.RestaurantTable": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
                 },
                 "id": {
                     "type": "integer"
@@ -1072,241 +4914,383 @@ const docTemplate = `{
                 "is_active": {
                     "type": "boolean"
                 },
+                "seats": {
+                    "type": "integer"
+                },
+                "table_number": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.RestoreResult": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object"
+                },
+                "from_audit_id": {
+                    "type": "integer"
+                },
+                "record_id": {
+                    "type": "integer"
+                },
+                "related": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RecordRef"
+                    }
+                },
+                "table": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Role": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_system": {
+                    "type": "boolean"
+                },
                 "name": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "number"
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "domain.Employee": {
+        "domain.Shift": {
             "type": "object",
             "properties": {
-                "email": {
+                "actual_revenue": {
+                    "type": "number"
+                },
+                "closed_at": {
                     "type": "string"
                 },
-                "full_name": {
+                "closed_by": {
+                    "type": "integer"
+                },
+                "expected_revenue": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
                     "type": "string"
                 },
-                "hired_at": {
+                "opened_at": {
+                    "type": "string"
+                },
+                "opened_by": {
+                    "type": "integer"
+                },
+                "revenue_discrepancy": {
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ShiftRevenue": {
+            "type": "object",
+            "properties": {
+                "avg_check": {
+                    "type": "number"
+                },
+                "closed_at": {
+                    "type": "string"
+                },
+                "opened_at": {
                     "type": "string"
                 },
-                "id": {
+                "orders_count": {
                     "type": "integer"
                 },
-                "is_active": {
-                    "type": "boolean"
+                "shift_id": {
+                    "type": "integer"
                 },
-                "phone": {
-                    "type": "string"
+                "total_refunds": {
+                    "type": "number"
                 },
-                "role_id": {
-                    "type": "integer"
+                "total_revenue": {
+                    "type": "number"
                 }
             }
         },
-        "domain.MenuCategory": {
+        "domain.StockDiscrepancy": {
             "type": "object",
             "properties": {
-                "description": {
-                    "type": "string"
+                "ledger_quantity": {
+                    "type": "number"
                 },
-                "id": {
+                "product_id": {
                     "type": "integer"
                 },
-                "is_active": {
-                    "type": "boolean"
-                },
-                "name": {
+                "product_name": {
                     "type": "string"
                 },
-                "sort_order": {
-                    "type": "integer"
+                "stock_quantity": {
+                    "type": "number"
                 }
             }
         },
-        "domain.Order": {
+        "domain.StockMovement": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "customer_id": {
+                "employee_id": {
                     "type": "integer"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "reservation_id": {
+                "movement_type": {
+                    "type": "string"
+                },
+                "order_id": {
                     "type": "integer"
                 },
-                "shift_id": {
+                "product_id": {
                     "type": "integer"
                 },
-                "status": {
-                    "type": "string"
+                "quantity_after": {
+                    "type": "number"
                 },
-                "table_id": {
-                    "type": "integer"
+                "quantity_delta": {
+                    "type": "number"
                 },
-                "waiter_id": {
-                    "type": "integer"
+                "reason": {
+                    "type": "string"
                 }
             }
         },
-        "domain.OrderItem": {
+        "domain.TableImport": {
             "type": "object",
             "properties": {
-                "comment": {
+                "description": {
                     "type": "string"
                 },
-                "dish_id": {
+                "is_active": {
+                    "type": "boolean"
+                },
+                "seats": {
                     "type": "integer"
                 },
-                "id": {
+                "table_number": {
                     "type": "integer"
+                }
+            }
+        },
+        "domain.WaiterPerformance": {
+            "type": "object",
+            "properties": {
+                "avg_check": {
+                    "type": "number"
                 },
-                "order_id": {
+                "full_name": {
+                    "type": "string"
+                },
+                "orders_count": {
                     "type": "integer"
                 },
-                "price_at_moment": {
+                "total_revenue": {
                     "type": "number"
                 },
-                "quantity": {
+                "waiter_id": {
                     "type": "integer"
                 }
             }
         },
-        "domain.Payment": {
+        "handlers.closeShiftRequest": {
             "type": "object",
             "properties": {
-                "amount": {
+                "actual_revenue": {
                     "type": "number"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "method": {
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.credentialsRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
                     "type": "string"
                 },
-                "order_id": {
-                    "type": "integer"
-                },
-                "paid_at": {
+                "pin": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.dismissImportRequest": {
+            "type": "object",
+            "properties": {
+                "created_before": {
                     "type": "string"
                 },
-                "status": {
+                "entity": {
                     "type": "string"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 }
             }
         },
-        "domain.Product": {
+        "handlers.ingredientQuantityRequest": {
             "type": "object",
             "properties": {
-                "cost_price": {
+                "quantity": {
                     "type": "number"
-                },
-                "id": {
+                }
+            }
+        },
+        "handlers.loginRequest": {
+            "type": "object",
+            "properties": {
+                "employee_id": {
+                    "description": "EmployeeID and PIN log in at a POS terminal.",
                     "type": "integer"
                 },
-                "is_available": {
-                    "type": "boolean"
+                "login": {
+                    "description": "Login (email or phone) and Password log in to the back office.",
+                    "type": "string"
                 },
-                "name": {
+                "password": {
                     "type": "string"
                 },
-                "unit": {
+                "pin": {
                     "type": "string"
                 }
             }
         },
-        "domain.Reservation": {
+        "handlers.loginResponse": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "customer_id": {
-                    "type": "integer"
+                "employee": {
+                    "$ref": "#/definitions/domain.Employee"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "reserved_from": {
+                "expires_at": {
                     "type": "string"
                 },
-                "reserved_to": {
+                "token": {
                     "type": "string"
-                },
-                "status": {
+                }
+            }
+        },
+        "handlers.openShiftRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
                     "type": "string"
-                },
-                "table_id": {
-                    "type": "integer"
                 }
             }
         },
-        "domain.RestaurantTable": {
+        "handlers.orderItemResponse": {
             "type": "object",
             "properties": {
-                "description": {
+                "comment": {
                     "type": "string"
                 },
+                "discount_percent": {
+                    "type": "number"
+                },
+                "dish_id": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "is_active": {
-                    "type": "boolean"
+                "order_id": {
+                    "type": "integer"
                 },
-                "seats": {
+                "price_at_moment": {
+                    "type": "number"
+                },
+                "price_override_by": {
+                    "description": "PriceOverrideReason marks an approved price and line discount (e.g. a\ncomp); without it PriceAtMoment is taken from the menu and no discount\nis applied. PriceOverrideBy is the approving employee, always the caller.",
                     "type": "integer"
                 },
-                "table_number": {
+                "price_override_reason": {
+                    "type": "string"
+                },
+                "quantity": {
                     "type": "integer"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "domain.ShiftRevenue": {
+        "handlers.orderPricingRequest": {
             "type": "object",
             "properties": {
-                "avg_check": {
+                "discount_percent": {
                     "type": "number"
                 },
-                "closed_at": {
-                    "type": "string"
-                },
-                "opened_at": {
-                    "type": "string"
-                },
-                "orders_count": {
-                    "type": "integer"
-                },
-                "shift_id": {
-                    "type": "integer"
-                },
-                "total_revenue": {
+                "service_charge_percent": {
                     "type": "number"
                 }
             }
         },
-        "domain.WaiterPerformance": {
+        "handlers.orderPricingResponse": {
             "type": "object",
             "properties": {
-                "avg_check": {
+                "line_discounts": {
                     "type": "number"
                 },
-                "full_name": {
-                    "type": "string"
+                "loyalty_discount": {
+                    "type": "number"
                 },
-                "orders_count": {
-                    "type": "integer"
+                "loyalty_discount_percent": {
+                    "type": "number"
                 },
-                "total_revenue": {
+                "order_discount": {
                     "type": "number"
                 },
-                "waiter_id": {
-                    "type": "integer"
+                "order_discount_percent": {
+                    "type": "number"
+                },
+                "service_charge": {
+                    "type": "number"
+                },
+                "service_charge_percent": {
+                    "type": "number"
+                },
+                "subtotal": {
+                    "type": "number"
+                },
+                "total": {
+                    "type": "number"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
@@ -1338,6 +5322,96 @@ const docTemplate = `{
                     "type": "integer"
                 }
             }
+        },
+        "handlers.ownCredentialsRequest": {
+            "type": "object",
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "current_pin": {
+                    "description": "CurrentPIN or CurrentPassword proves that the token holder knows the\nsecret being replaced.",
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "pin": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.payPaymentRequest": {
+            "type": "object",
+            "properties": {
+                "method": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.refundRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.restoreRequest": {
+            "type": "object",
+            "properties": {
+                "audit_id": {
+                    "description": "AuditID is the audit_log entry to restore; omit it to un-delete the record.",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.retryImportRequest": {
+            "type": "object",
+            "properties": {
+                "raw_data": {
+                    "description": "RawData replaces the stored row before the retry; omit it to retry as is.",
+                    "type": "object"
+                }
+            }
+        },
+        "handlers.stockAdjustmentRequest": {
+            "type": "object",
+            "properties": {
+                "counted_quantity": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.stockMovementRequest": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
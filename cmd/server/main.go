@@ -12,6 +12,7 @@ import (
 	"github.com/example/rms/internal/config"
 	"github.com/example/rms/internal/db"
 	api "github.com/example/rms/internal/http"
+	"github.com/example/rms/internal/jobs"
 	"github.com/example/rms/internal/repository"
 )
 
@@ -19,14 +20,32 @@ import (
 // @version 1.0
 // @description REST API for restaurant hall, orders, and warehouse management
 // @BasePath /api
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 func main() {
 	cfg := config.Load()
 	database := db.MustConnect(cfg)
 	defer database.Close()
 
-	repo := repository.New(database)
+	repo := repository.New(database, cfg)
+	if cfg.BootstrapAdminPhone != "" {
+		created, err := repo.BootstrapAdmin(context.Background(), cfg.BootstrapAdminName, cfg.BootstrapAdminPhone, cfg.BootstrapAdminPassword)
+		if err != nil {
+			log.Fatalf("bootstrap admin: %v", err)
+		}
+		if created {
+			log.Printf("Admin login created for %s", cfg.BootstrapAdminPhone)
+		}
+	}
 	router := api.NewRouter(repo)
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	if cfg.NoShowGrace > 0 {
+		go jobs.RunNoShowMarker(jobsCtx, repo, cfg.NoShowGrace, cfg.NoShowCheckInterval)
+	}
+
 	srv := &http.Server{
 		Addr:    ":" + cfg.HTTPPort,
 		Handler: router,
@@ -43,6 +62,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
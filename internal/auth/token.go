@@ -0,0 +1,61 @@
+// Package auth issues and verifies employee session tokens.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// claims is the token payload: the employee id and the expiry as a Unix time.
+type claims struct {
+	EmployeeID int64 `json:"sub"`
+	ExpiresAt  int64 `json:"exp"`
+}
+
+// Sign returns a token for the employee that is valid until expires. The
+// token is the base64url payload and its HMAC-SHA256 joined by a dot.
+func Sign(secret string, employeeID int64, expires time.Time) string {
+	payload, _ := json.Marshal(claims{EmployeeID: employeeID, ExpiresAt: expires.Unix()})
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + base64.RawURLEncoding.EncodeToString(mac(secret, body))
+}
+
+// Verify checks the token signature and expiry and returns the employee id.
+func Verify(secret, token string, now time.Time) (int64, error) {
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, ErrInvalidToken
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, mac(secret, body)) {
+		return 0, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil || c.EmployeeID == 0 {
+		return 0, ErrInvalidToken
+	}
+	if now.Unix() >= c.ExpiresAt {
+		return 0, ErrTokenExpired
+	}
+	return c.EmployeeID, nil
+}
+
+func mac(secret, body string) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(body))
+	return h.Sum(nil)
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	valid := Sign(testSecret, 42, now.Add(time.Hour))
+	body, sig, _ := strings.Cut(valid, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":1,"exp":4102444800}`))
+
+	tests := []struct {
+		name    string
+		secret  string
+		token   string
+		now     time.Time
+		want    int64
+		wantErr error
+	}{
+		{name: "valid token", secret: testSecret, token: valid, now: now, want: 42},
+		{name: "one second before expiry", secret: testSecret, token: valid, now: now.Add(time.Hour - time.Second), want: 42},
+		{name: "at expiry", secret: testSecret, token: valid, now: now.Add(time.Hour), wantErr: ErrTokenExpired},
+		{name: "expired", secret: testSecret, token: Sign(testSecret, 42, now.Add(-time.Minute)), now: now, wantErr: ErrTokenExpired},
+		{name: "other secret", secret: testSecret + "x", token: valid, now: now, wantErr: ErrInvalidToken},
+		{name: "tampered payload", secret: testSecret, token: forged + "." + sig, now: now, wantErr: ErrInvalidToken},
+		{name: "tampered signature", secret: testSecret, token: body + "." + flipFirst(sig), now: now, wantErr: ErrInvalidToken},
+		{name: "missing signature", secret: testSecret, token: body, now: now, wantErr: ErrInvalidToken},
+		{name: "empty signature", secret: testSecret, token: body + ".", now: now, wantErr: ErrInvalidToken},
+		{name: "signature not base64", secret: testSecret, token: body + ".***", now: now, wantErr: ErrInvalidToken},
+		{name: "empty token", secret: testSecret, token: "", now: now, wantErr: ErrInvalidToken},
+		{name: "signed payload without employee", secret: testSecret, token: Sign(testSecret, 0, now.Add(time.Hour)), now: now, wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Verify(tt.secret, tt.token, tt.now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("employee = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// flipFirst changes the first character of a base64url string. The last one
+// may only carry padding bits that decoding ignores.
+func flipFirst(s string) string {
+	if s[0] == 'A' {
+		return "B" + s[1:]
+	}
+	return "A" + s[1:]
+}
@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Config holds application configuration loaded from environment.
@@ -15,8 +17,45 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	HTTPPort   string
+	// AuthSecret signs session tokens.
+	AuthSecret string
+	// AuthTokenTTL is how long a session token stays valid after login.
+	AuthTokenTTL time.Duration
+	// AuthMaxFailedLogins is how many wrong secrets in a row lock an
+	// employee's login for AuthLockout; every further failure doubles the lock.
+	AuthMaxFailedLogins int
+	AuthLockout         time.Duration
+	// BootstrapAdminPhone and BootstrapAdminPassword create the first admin
+	// login at startup while no active admin has a password.
+	BootstrapAdminPhone    string
+	BootstrapAdminPassword string
+	BootstrapAdminName     string
+
+	// RequireOpenShift makes order creation fail when no shift is open
+	// instead of storing the order without a shift.
+	RequireOpenShift bool
+	// StockDeductOn is the order status ("in_progress" or "closed") at which
+	// ingredients are deducted from stock.
+	StockDeductOn string
+	// StockShortagePolicy decides what happens when stock is insufficient:
+	// "reject", "allow_negative" or "clamp".
+	StockShortagePolicy string
+	// NoShowGrace is how long after reserved_from a confirmed reservation without
+	// an order is marked as no_show; zero disables the background job.
+	NoShowGrace time.Duration
+	// NoShowCheckInterval is how often the no-show job runs.
+	NoShowCheckInterval time.Duration
+	// ServiceChargePercent is the service charge applied to new orders.
+	ServiceChargePercent float64
+	// LoyaltyThresholds is the total spend needed for VIP levels 1, 2 and 3.
+	LoyaltyThresholds []float64
+	// LoyaltyDiscounts is the order discount percentage for VIP levels 0 to 3.
+	LoyaltyDiscounts []float64
 }
 
+// minAuthSecretLength keeps token signing keys out of guessing range.
+const minAuthSecretLength = 32
+
 // Load reads environment variables with sensible defaults for local development.
 func Load() *Config {
 	loadEnvFile(".env")
@@ -28,6 +67,50 @@ func Load() *Config {
 		DBPassword: mustEnv("DB_PASSWORD"),
 		DBName:     mustEnv("DB_NAME"),
 		HTTPPort:   mustEnv("HTTP_PORT"),
+		AuthSecret: mustEnv("AUTH_SECRET"),
+
+		AuthTokenTTL:        envDuration("AUTH_TOKEN_TTL", 12*time.Hour),
+		AuthMaxFailedLogins: envInt("AUTH_MAX_FAILED_LOGINS", 5),
+		AuthLockout:         envDuration("AUTH_LOCKOUT", time.Minute),
+
+		BootstrapAdminPhone:    os.Getenv("BOOTSTRAP_ADMIN_PHONE"),
+		BootstrapAdminPassword: os.Getenv("BOOTSTRAP_ADMIN_PASSWORD"),
+		BootstrapAdminName:     envOrDefault("BOOTSTRAP_ADMIN_NAME", "Администратор"),
+
+		RequireOpenShift:    envBool("ORDERS_REQUIRE_OPEN_SHIFT", true),
+		StockDeductOn:       envOneOf("STOCK_DEDUCT_ON", "in_progress", "in_progress", "closed"),
+		StockShortagePolicy: envOneOf("STOCK_SHORTAGE_POLICY", "reject", "reject", "allow_negative", "clamp"),
+		NoShowGrace:         envDuration("RESERVATION_NO_SHOW_GRACE", 30*time.Minute),
+		NoShowCheckInterval: envDuration("RESERVATION_NO_SHOW_CHECK_INTERVAL", time.Minute),
+
+		ServiceChargePercent: envPercent("SERVICE_CHARGE_PERCENT", 0),
+		LoyaltyThresholds:    envFloats("LOYALTY_THRESHOLDS", "10000,50000,150000", 3),
+		LoyaltyDiscounts:     envFloats("LOYALTY_DISCOUNTS", "0,3,5,10", 4),
+	}
+	for i := 1; i < len(cfg.LoyaltyThresholds); i++ {
+		if cfg.LoyaltyThresholds[i] <= cfg.LoyaltyThresholds[i-1] {
+			log.Fatalf("environment variable LOYALTY_THRESHOLDS must be increasing")
+		}
+	}
+	for _, d := range cfg.LoyaltyDiscounts {
+		if d < 0 || d > 100 {
+			log.Fatalf("environment variable LOYALTY_DISCOUNTS must hold percentages between 0 and 100")
+		}
+	}
+	if len(cfg.AuthSecret) < minAuthSecretLength {
+		log.Fatalf("environment variable AUTH_SECRET must be at least %d bytes", minAuthSecretLength)
+	}
+	if cfg.AuthMaxFailedLogins <= 0 || cfg.AuthLockout <= 0 {
+		log.Fatalf("environment variables AUTH_MAX_FAILED_LOGINS and AUTH_LOCKOUT must be positive")
+	}
+	if (cfg.BootstrapAdminPhone == "") != (cfg.BootstrapAdminPassword == "") {
+		log.Fatalf("environment variables BOOTSTRAP_ADMIN_PHONE and BOOTSTRAP_ADMIN_PASSWORD must be set together")
+	}
+	if cfg.NoShowCheckInterval <= 0 {
+		log.Fatalf("environment variable RESERVATION_NO_SHOW_CHECK_INTERVAL must be positive")
+	}
+	if cfg.NoShowGrace < 0 {
+		log.Fatalf("environment variable RESERVATION_NO_SHOW_GRACE must not be negative")
 	}
 
 	return cfg
@@ -66,3 +149,69 @@ func mustEnv(key string) string {
 	log.Fatalf("environment variable %s is required (set it in .env or the environment)", key)
 	return ""
 }
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func envBool(key string, def bool) bool {
+	v, err := strconv.ParseBool(envOrDefault(key, strconv.FormatBool(def)))
+	if err != nil {
+		log.Fatalf("environment variable %s must be a boolean: %v", key, err)
+	}
+	return v
+}
+
+func envOneOf(key, def string, allowed ...string) string {
+	v := envOrDefault(key, def)
+	for _, a := range allowed {
+		if v == a {
+			return v
+		}
+	}
+	log.Fatalf("environment variable %s must be one of %s, got %q", key, strings.Join(allowed, ", "), v)
+	return ""
+}
+
+func envInt(key string, def int) int {
+	v, err := strconv.Atoi(envOrDefault(key, strconv.Itoa(def)))
+	if err != nil {
+		log.Fatalf("environment variable %s must be an integer: %v", key, err)
+	}
+	return v
+}
+
+func envPercent(key string, def float64) float64 {
+	v, err := strconv.ParseFloat(envOrDefault(key, strconv.FormatFloat(def, 'f', -1, 64)), 64)
+	if err != nil || v < 0 || v > 100 {
+		log.Fatalf("environment variable %s must be a percentage between 0 and 100, got %q", key, os.Getenv(key))
+	}
+	return v
+}
+
+func envFloats(key, def string, n int) []float64 {
+	parts := strings.Split(envOrDefault(key, def), ",")
+	if len(parts) != n {
+		log.Fatalf("environment variable %s must hold %d comma-separated numbers", key, n)
+	}
+	res := make([]float64, n)
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil || v < 0 {
+			log.Fatalf("environment variable %s must hold non-negative numbers, got %q", key, p)
+		}
+		res[i] = v
+	}
+	return res
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	v, err := time.ParseDuration(envOrDefault(key, def.String()))
+	if err != nil {
+		log.Fatalf("environment variable %s must be a duration like 30m: %v", key, err)
+	}
+	return v
+}
@@ -1,6 +1,16 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
+
+// Page is the envelope of list endpoints; Items holds a slice of the listed
+// entity and NextCursor is empty on the last page.
+type Page struct {
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
 
 type Role struct {
 	ID        int64     `json:"id"`
@@ -8,6 +18,7 @@ type Role struct {
 	Description string  `json:"description,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	IsSystem  bool      `json:"is_system"`
+	Permissions []string `json:"permissions"`
 }
 
 type Employee struct {
@@ -26,7 +37,44 @@ type Customer struct {
 	Phone     string    `json:"phone"`
 	Email     *string   `json:"email,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
-	VIPLevel  int       `json:"vip_level"`
+	// VIPLevel is set by the loyalty engine from the customer's spend; create,
+	// update and import ignore it.
+	VIPLevel int `json:"vip_level" readonly:"true"`
+}
+
+// CustomerDetails is the customer profile used by front-of-house.
+type CustomerDetails struct {
+	Customer
+	Loyalty         CustomerLoyalty `json:"loyalty"`
+	VisitsCount     int64           `json:"visits_count"`
+	LastVisitAt     *time.Time      `json:"last_visit_at,omitempty"`
+	Reservations    []Reservation   `json:"reservations"`
+	Orders          []CustomerOrder `json:"orders"`
+	FavouriteDishes []FavouriteDish `json:"favourite_dishes"`
+}
+
+// CustomerOrder is an order in the customer's history with its grand total.
+type CustomerOrder struct {
+	Order
+	Total float64 `json:"total"`
+}
+
+type FavouriteDish struct {
+	DishID      int64  `json:"dish_id"`
+	Name        string `json:"name"`
+	Portions    int64  `json:"portions"`
+	OrdersCount int64  `json:"orders_count"`
+}
+
+// CustomerLoyalty shows the spend behind the VIP level and what the next level needs.
+// The Next* fields are omitted at the top level.
+type CustomerLoyalty struct {
+	TotalSpent       float64  `json:"total_spent"`
+	Level            int      `json:"level"`
+	DiscountPercent  float64  `json:"discount_percent"`
+	NextLevel        *int     `json:"next_level,omitempty"`
+	NextLevelSpend   *float64 `json:"next_level_spend,omitempty"`
+	SpendToNextLevel *float64 `json:"spend_to_next_level,omitempty"`
 }
 
 type RestaurantTable struct {
@@ -54,9 +102,31 @@ type Product struct {
 }
 
 type ProductStock struct {
-	ProductID int64     `json:"product_id"`
-	Quantity  float64   `json:"quantity"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ProductID   int64     `json:"product_id"`
+	ProductName string    `json:"product_name,omitempty"`
+	Unit        string    `json:"unit,omitempty"`
+	Quantity    float64   `json:"quantity"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type StockMovement struct {
+	ID            int64     `json:"id"`
+	ProductID     int64     `json:"product_id"`
+	MovementType  string    `json:"movement_type"`
+	QuantityDelta float64   `json:"quantity_delta"`
+	QuantityAfter float64   `json:"quantity_after"`
+	Reason        string    `json:"reason,omitempty"`
+	EmployeeID    *int64    `json:"employee_id,omitempty"`
+	OrderID       *int64    `json:"order_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// StockDiscrepancy shows a product whose stock does not match its movement history.
+type StockDiscrepancy struct {
+	ProductID      int64   `json:"product_id"`
+	ProductName    string  `json:"product_name"`
+	StockQuantity  float64 `json:"stock_quantity"`
+	LedgerQuantity float64 `json:"ledger_quantity"`
 }
 
 type Dish struct {
@@ -96,6 +166,7 @@ type Shift struct {
 	Note            string     `json:"note,omitempty"`
 	ExpectedRevenue *float64   `json:"expected_revenue,omitempty"`
 	ActualRevenue   *float64   `json:"actual_revenue,omitempty"`
+	Discrepancy     *float64   `json:"revenue_discrepancy,omitempty"`
 }
 
 type Order struct {
@@ -107,6 +178,41 @@ type Order struct {
 	ShiftID       *int64    `json:"shift_id,omitempty"`
 	CreatedAt     time.Time `json:"created_at"`
 	Status        string    `json:"status"`
+	// DiscountPercent applies to the whole order after line discounts;
+	// LoyaltyDiscountPercent comes from the customer's VIP level and
+	// ServiceChargePercent from the configuration, both fixed at creation.
+	DiscountPercent        float64 `json:"discount_percent"`
+	LoyaltyDiscountPercent float64 `json:"loyalty_discount_percent"`
+	ServiceChargePercent   float64 `json:"service_charge_percent"`
+}
+
+// OrderPricing is the server-side breakdown of an order bill.
+type OrderPricing struct {
+	Subtotal               float64 `json:"subtotal"`
+	LineDiscounts          float64 `json:"line_discounts"`
+	OrderDiscountPercent   float64 `json:"order_discount_percent"`
+	OrderDiscount          float64 `json:"order_discount"`
+	LoyaltyDiscountPercent float64 `json:"loyalty_discount_percent"`
+	LoyaltyDiscount        float64 `json:"loyalty_discount"`
+	ServiceChargePercent   float64 `json:"service_charge_percent"`
+	ServiceCharge          float64 `json:"service_charge"`
+	Total                  float64 `json:"total"`
+}
+
+type OrderStatusChange struct {
+	ID         int64     `json:"id"`
+	OrderID    int64     `json:"order_id"`
+	FromStatus *string   `json:"from_status,omitempty"`
+	ToStatus   string    `json:"to_status"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// OrderDetails is an order with its items and status history.
+type OrderDetails struct {
+	Order
+	Pricing       OrderPricing        `json:"pricing"`
+	Items         []OrderItem         `json:"items"`
+	StatusHistory []OrderStatusChange `json:"status_history"`
 }
 
 type OrderItem struct {
@@ -116,6 +222,12 @@ type OrderItem struct {
 	Quantity       int     `json:"quantity"`
 	PriceAtMoment  float64 `json:"price_at_moment"`
 	Comment        string  `json:"comment,omitempty"`
+	// PriceOverrideReason marks an approved price and line discount (e.g. a
+	// comp); without it PriceAtMoment is taken from the menu and no discount
+	// is applied. PriceOverrideBy is the approving employee, always the caller.
+	PriceOverrideBy     *int64  `json:"price_override_by,omitempty"`
+	PriceOverrideReason string  `json:"price_override_reason,omitempty"`
+	DiscountPercent     float64 `json:"discount_percent,omitempty"`
 }
 
 type Payment struct {
@@ -127,12 +239,120 @@ type Payment struct {
 	Status  string    `json:"status"`
 }
 
+// Refund returns part or all of a paid payment; refunds are never edited, only added.
+type Refund struct {
+	ID         int64     `json:"id"`
+	PaymentID  int64     `json:"payment_id"`
+	OrderID    int64     `json:"order_id"`
+	Amount     float64   `json:"amount"`
+	Reason     string    `json:"reason"`
+	ApprovedBy int64     `json:"approved_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// OrderBalance shows how much of an order is covered by its payments.
+type OrderBalance struct {
+	OrderID     int64     `json:"order_id"`
+	Total       float64   `json:"total"`
+	Paid        float64   `json:"paid"`
+	Pending     float64   `json:"pending"`
+	Outstanding float64   `json:"outstanding"`
+	Refunded    float64   `json:"refunded"`
+	Payments    []Payment `json:"payments"`
+}
+
+// BillSplit describes how an order bill is divided into pending payments.
+// Mode "even" uses Parts and Method, "items" uses Groups, "custom" uses Shares.
+type BillSplit struct {
+	Mode   string           `json:"mode"`
+	Parts  int              `json:"parts,omitempty"`
+	Method string           `json:"method,omitempty"`
+	Groups []BillSplitGroup `json:"groups,omitempty"`
+	Shares []BillSplitShare `json:"shares,omitempty"`
+}
+
+type BillSplitGroup struct {
+	ItemIDs []int64 `json:"item_ids"`
+	Method  string  `json:"method"`
+}
+
+type BillSplitShare struct {
+	Amount float64 `json:"amount"`
+	Method string  `json:"method"`
+}
+
+// MenuCategoryImport is a menu category row of a batch import. An omitted
+// IsActive means active.
+type MenuCategoryImport struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	SortOrder   int    `json:"sort_order"`
+	IsActive    *bool  `json:"is_active,omitempty"`
+}
+
+// TableImport is a restaurant table row of a batch import. An omitted IsActive
+// means active.
+type TableImport struct {
+	TableNumber int    `json:"table_number"`
+	Seats       int    `json:"seats"`
+	IsActive    *bool  `json:"is_active,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// DishImport is a dish row of a batch import; the category is given by name.
+// An omitted IsActive means active.
+type DishImport struct {
+	Category        string  `json:"category"`
+	Name            string  `json:"name"`
+	Price           float64 `json:"price"`
+	CookTimeMinutes int     `json:"cook_time_minutes"`
+	IsActive        *bool   `json:"is_active,omitempty"`
+	Description     string  `json:"description,omitempty"`
+}
+
+// DishIngredientImport is a recipe row of a batch import. The dish is found by
+// name within Category; Category may be omitted when the dish name is unique.
+type DishIngredientImport struct {
+	Category string  `json:"category,omitempty"`
+	Dish     string  `json:"dish"`
+	Product  string  `json:"product"`
+	Quantity float64 `json:"quantity"`
+}
+
+// EmployeeImport is an employee row of a batch import; the role is given by
+// name and HiredAt as YYYY-MM-DD (today when omitted). An omitted IsActive
+// means active.
+type EmployeeImport struct {
+	FullName string  `json:"full_name"`
+	Phone    string  `json:"phone"`
+	Email    *string `json:"email,omitempty"`
+	Role     string  `json:"role"`
+	HiredAt  string  `json:"hired_at,omitempty"`
+	IsActive *bool   `json:"is_active,omitempty"`
+}
+
+// ImportResult counts what a batch import committed: rows inserted, rows
+// updated by natural key and rows that failed and were logged to
+// import_errors under ErrorIDs.
+type ImportResult struct {
+	Total    int     `json:"total"`
+	Inserted int     `json:"inserted"`
+	Updated  int     `json:"updated"`
+	Failed   int     `json:"failed"`
+	ErrorIDs []int64 `json:"error_ids"`
+}
+
+// ImportError is a row that failed to import. Status is "open" until a retry
+// succeeds ("resolved") or the row is dismissed ("dismissed").
 type ImportError struct {
-	ID           int64     `json:"id"`
-	CreatedAt    time.Time `json:"created_at"`
-	Entity       string    `json:"entity"`
-	RawData      string    `json:"raw_data"`
-	ErrorMessage string    `json:"error_message"`
+	ID           int64           `json:"id"`
+	CreatedAt    time.Time       `json:"created_at"`
+	Entity       string          `json:"entity"`
+	RawData      json.RawMessage `json:"raw_data" swaggertype:"object"`
+	ErrorMessage string          `json:"error_message"`
+	Status       string          `json:"status"`
+	ResolvedAt   *time.Time      `json:"resolved_at,omitempty"`
+	ResolvedBy   *int64          `json:"resolved_by,omitempty"`
 }
 
 type ShiftRevenue struct {
@@ -142,6 +362,7 @@ type ShiftRevenue struct {
 	OrdersCount  int64      `json:"orders_count"`
 	TotalRevenue float64    `json:"total_revenue"`
 	AvgCheck     *float64   `json:"avg_check,omitempty"`
+	TotalRefunds float64    `json:"total_refunds"`
 }
 
 type WaiterPerformance struct {
@@ -160,3 +381,55 @@ type DishAvailability struct {
 	AllProductsAvailable bool    `json:"all_products_available"`
 	CanBeOrdered         bool    `json:"can_be_ordered"`
 }
+
+// AuditEntry is a row of audit_log: the record's state before and after a
+// change made by ChangedBy (an employee id, or the database user for changes
+// made outside the API).
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	Table     string          `json:"table"`
+	RecordID  int64           `json:"record_id"`
+	Operation string          `json:"operation"`
+	ChangedAt time.Time       `json:"changed_at"`
+	ChangedBy string          `json:"changed_by"`
+	RequestID *string         `json:"request_id,omitempty"`
+	OldData   json.RawMessage `json:"old_data,omitempty" swaggertype:"object"`
+	NewData   json.RawMessage `json:"new_data,omitempty" swaggertype:"object"`
+}
+
+// RecordVersion is one change of a record in its history with the fields
+// that differ from the previous version; Old is null for inserts and New is
+// null for deletes.
+type RecordVersion struct {
+	AuditID   int64         `json:"audit_id"`
+	Table     string        `json:"table"`
+	RecordID  int64         `json:"record_id"`
+	Operation string        `json:"operation"`
+	ChangedAt time.Time     `json:"changed_at"`
+	ChangedBy string        `json:"changed_by"`
+	RequestID *string       `json:"request_id,omitempty"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+// RestoreResult is the record after a restore from audit entry FromAuditID,
+// with the rows that the original delete had removed or unlinked.
+type RestoreResult struct {
+	Table       string          `json:"table"`
+	RecordID    int64           `json:"record_id"`
+	FromAuditID int64           `json:"from_audit_id"`
+	Data        json.RawMessage `json:"data" swaggertype:"object"`
+	Related     []RecordRef     `json:"related"`
+}
+
+// RecordRef names a row touched by a restore; Action is "restored" or "relinked".
+type RecordRef struct {
+	Table  string `json:"table"`
+	ID     int64  `json:"id"`
+	Action string `json:"action"`
+}
+
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old" swaggertype:"object"`
+	New   json.RawMessage `json:"new" swaggertype:"object"`
+}
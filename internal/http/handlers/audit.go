@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAudit registers audit log endpoints.
+func RegisterAudit(r *gin.RouterGroup, h *Handler) {
+	g := r.Group("/audit", h.authorize("audit"))
+	g.GET("", h.listAudit)
+	g.GET("/:table/:id", h.getRecordHistory)
+	g.POST("/:table/:id/restore", h.restoreRecord)
+}
+
+// listAudit godoc
+// @Summary Browse the audit log
+// @Description Newest first by default; sortable by id, changed_at. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.
+// @Tags audit
+// @Produce json
+// @Param table query string false "table name, e.g. orders"
+// @Param record_id query int false "record id"
+// @Param operation query string false "INSERT, UPDATE or DELETE"
+// @Param actor query string false "employee id that made the change"
+// @Param request_id query string false "request id"
+// @Param changed_at[gte] query string false "changed from (timestamp)"
+// @Param changed_at[lte] query string false "changed until (timestamp)"
+// @Param sort query string false "sort field, prefix - for descending"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "page size, up to 200"
+// @Success 200 {object} domain.Page{items=[]domain.AuditEntry}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /audit [get]
+func (h *Handler) listAudit(c *gin.Context) {
+	page, err := h.Repo.ListAudit(c.Request.Context(), parseListQuery(c))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// getRecordHistory godoc
+// @Summary Field-level change history of a record
+// @Tags audit
+// @Produce json
+// @Param table path string true "audited table, e.g. customers"
+// @Param id path int true "record id"
+// @Success 200 {array} domain.RecordVersion
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /audit/{table}/{id} [get]
+func (h *Handler) getRecordHistory(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	history, err := h.Repo.GetRecordHistory(c.Request.Context(), c.Param("table"), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// getOrderHistory godoc
+// @Summary Change history of order, its items and payments
+// @Description Includes removed items and edited or deleted payments. Requires audit:read.
+// @Tags orders
+// @Produce json
+// @Param id path int true "order id"
+// @Success 200 {array} domain.RecordVersion
+// @Security BearerAuth
+// @Router /orders/{id}/history [get]
+func (h *Handler) getOrderHistory(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	history, err := h.Repo.GetOrderHistory(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// getPaymentHistory godoc
+// @Summary Change history of payment and its refunds
+// @Description Requires audit:read.
+// @Tags payments
+// @Produce json
+// @Param id path int true "payment id"
+// @Success 200 {array} domain.RecordVersion
+// @Security BearerAuth
+// @Router /payments/{id}/history [get]
+func (h *Handler) getPaymentHistory(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	history, err := h.Repo.GetPaymentHistory(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+type restoreRequest struct {
+	// AuditID is the audit_log entry to restore; omit it to un-delete the record.
+	AuditID int64 `json:"audit_id"`
+}
+
+// restoreRecord godoc
+// @Summary Restore a record from the audit log
+// @Description Restores a customer, dish, recipe line, product or reservation to the state recorded by audit_id, or un-deletes it from its last DELETE snapshot when audit_id is omitted. Reservations, recipe lines and order links removed by the same request are restored too. Live reservations only change status along the status machine. Requires audit:restore.
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param table path string true "customers, dishes, dish_ingredients, products or reservations"
+// @Param id path int true "record id"
+// @Param restore body restoreRequest false "audit version"
+// @Success 200 {object} domain.RestoreResult
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /audit/{table}/{id}/restore [post]
+func (h *Handler) restoreRecord(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var req restoreRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	res, err := h.Repo.RestoreRecord(c.Request.Context(), c.Param("table"), id, req.AuditID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
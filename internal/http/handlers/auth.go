@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/rms/internal/auth"
+	"github.com/example/rms/internal/domain"
+	"github.com/example/rms/internal/repository"
+)
+
+// routePermissions names the permission for routes whose action is not the
+// plain read/create/update/delete implied by the HTTP method.
+var routePermissions = map[string]string{
+	"POST /api/orders/:id/items":                    "orders:update",
+	"DELETE /api/orders/:id/items/:itemId":          "orders:update",
+	"PUT /api/orders/:id/pricing":                   "orders:discount",
+	"GET /api/orders/:id/payments":                  "payments:read",
+	"POST /api/orders/:id/split":                    "payments:create",
+	"POST /api/payments/:id/pay":                    "payments:create",
+	"GET /api/payments/:id/refunds":                 "payments:read",
+	"POST /api/payments/:id/refunds":                "payments:refund",
+	"POST /api/dishes/:id/ingredients":              "dishes:update",
+	"DELETE /api/dishes/:id/ingredients/:productId": "dishes:update",
+	"POST /api/shifts/open":                         "shifts:open",
+	"POST /api/shifts/:id/close":                    "shifts:close",
+	"GET /api/orders/:id/history":                   "audit:read",
+	"GET /api/payments/:id/history":                 "audit:read",
+	"POST /api/audit/:table/:id/restore":            "audit:restore",
+	"POST /api/import-errors/:id/retry":             "import:create",
+	"POST /api/import-errors/dismiss":               "import:update",
+	"POST /api/batch-import/employees":              "employees:create",
+}
+
+var methodActions = map[string]string{
+	http.MethodGet:    "read",
+	http.MethodPost:   "create",
+	http.MethodPut:    "update",
+	http.MethodDelete: "delete",
+}
+
+// RegisterAuth registers login and session endpoints.
+func RegisterAuth(r *gin.RouterGroup, h *Handler) {
+	g := r.Group("/auth")
+	g.POST("/login", h.login)
+	g.GET("/me", h.authenticate(), h.getMe)
+	g.PUT("/credentials", h.authenticate(), h.setOwnCredentials)
+}
+
+// authenticate returns middleware that accepts a valid, unexpired bearer token
+// of an active employee and puts the employee id into the request context.
+func (h *Handler) authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := h.authenticated(c); ok {
+			c.Next()
+		}
+	}
+}
+
+// authorize returns middleware that additionally requires the employee's role
+// to hold the route's permission on resource.
+func (h *Handler) authorize(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		employeeID, ok := h.authenticated(c)
+		if !ok {
+			return
+		}
+		permission, ok := routePermissions[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			permission = resource + ":" + methodActions[c.Request.Method]
+		}
+		if err := h.Repo.Authorize(c.Request.Context(), employeeID, permission); err != nil {
+			writeError(c, err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// authenticated verifies the bearer token; the employee is looked up on every
+// request so that deactivation takes effect immediately. On failure it aborts
+// the request with 401.
+func (h *Handler) authenticated(c *gin.Context) (int64, bool) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "bearer token is required"})
+		return 0, false
+	}
+	employeeID, err := auth.Verify(h.Repo.Cfg.AuthSecret, token, time.Now())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return 0, false
+	}
+	if _, err := h.Repo.GetActiveEmployee(c.Request.Context(), employeeID); err != nil {
+		writeError(c, err)
+		c.Abort()
+		return 0, false
+	}
+	c.Request = c.Request.WithContext(repository.WithEmployee(c.Request.Context(), employeeID))
+	return employeeID, true
+}
+
+type loginRequest struct {
+	// EmployeeID and PIN log in at a POS terminal.
+	EmployeeID int64  `json:"employee_id"`
+	PIN        string `json:"pin"`
+	// Login (email or phone) and Password log in to the back office.
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+type loginResponse struct {
+	Token     string          `json:"token"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	Employee  domain.Employee `json:"employee"`
+}
+
+// login godoc
+// @Summary Log in with PIN or password
+// @Description Send employee_id and pin (POS) or login and password (back office). Pass the token as "Authorization: Bearer <token>".
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body loginRequest true "credentials"
+// @Success 200 {object} loginResponse
+// @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /auth/login [post]
+func (h *Handler) login(c *gin.Context) {
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var employee domain.Employee
+	var err error
+	switch {
+	case req.EmployeeID != 0 && req.PIN != "":
+		employee, err = h.Repo.LoginWithPIN(c.Request.Context(), req.EmployeeID, req.PIN)
+	case req.Login != "" && req.Password != "":
+		employee, err = h.Repo.LoginWithPassword(c.Request.Context(), req.Login, req.Password)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "employee_id and pin or login and password are required"})
+		return
+	}
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	expires := time.Now().Add(h.Repo.Cfg.AuthTokenTTL)
+	c.JSON(http.StatusOK, loginResponse{
+		Token:     auth.Sign(h.Repo.Cfg.AuthSecret, employee.ID, expires),
+		ExpiresAt: expires,
+		Employee:  employee,
+	})
+}
+
+// getMe godoc
+// @Summary Current employee and their role
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/me [get]
+func (h *Handler) getMe(c *gin.Context) {
+	employeeID, _ := repository.EmployeeFromContext(c.Request.Context())
+	employee, err := h.Repo.GetActiveEmployee(c.Request.Context(), employeeID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	role, err := h.Repo.GetRole(c.Request.Context(), employee.RoleID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"employee": employee, "role": role})
+}
+
+type credentialsRequest struct {
+	PIN      string `json:"pin"`
+	Password string `json:"password"`
+}
+
+type ownCredentialsRequest struct {
+	credentialsRequest
+	// CurrentPIN or CurrentPassword proves that the token holder knows the
+	// secret being replaced.
+	CurrentPIN      string `json:"current_pin"`
+	CurrentPassword string `json:"current_password"`
+}
+
+// setOwnCredentials godoc
+// @Summary Change own PIN or password
+// @Description Requires current_pin or current_password. PIN is 4 to 8 digits, password at least 8 characters; an omitted field is kept.
+// @Tags auth
+// @Accept json
+// @Param credentials body ownCredentialsRequest true "current and new credentials"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/credentials [put]
+func (h *Handler) setOwnCredentials(c *gin.Context) {
+	var req ownCredentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.CurrentPIN == "" && req.CurrentPassword == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "current_pin or current_password is required"})
+		return
+	}
+	employeeID, _ := repository.EmployeeFromContext(c.Request.Context())
+	if err := h.Repo.CheckCredentials(c.Request.Context(), employeeID, req.CurrentPIN, req.CurrentPassword); err != nil {
+		writeError(c, err)
+		return
+	}
+	h.setCredentials(c, employeeID, req.credentialsRequest)
+}
+
+// setEmployeeCredentials godoc
+// @Summary Set employee PIN or password
+// @Description Requires employees:update. PIN is 4 to 8 digits, password at least 8 characters; an omitted field is kept.
+// @Tags employees
+// @Accept json
+// @Param id path int true "employee id"
+// @Param credentials body credentialsRequest true "new credentials"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /employees/{id}/credentials [put]
+func (h *Handler) setEmployeeCredentials(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var req credentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.setCredentials(c, id, req)
+}
+
+func (h *Handler) setCredentials(c *gin.Context, employeeID int64, req credentialsRequest) {
+	if req.PIN == "" && req.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pin or password is required"})
+		return
+	}
+	if err := h.Repo.SetEmployeeCredentials(c.Request.Context(), employeeID, req.PIN, req.Password); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/csv"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
@@ -14,68 +15,287 @@ import (
 
 // RegisterBatchImport registers batch-import endpoints.
 func RegisterBatchImport(r *gin.RouterGroup, h *Handler) {
-	g := r.Group("/batch-import")
+	g := r.Group("/batch-import", h.authorize("import"))
 	g.POST("/products", h.batchImportProducts)
+	g.POST("/menu-categories", h.batchImportMenuCategories)
+	g.POST("/tables", h.batchImportTables)
+	g.POST("/dishes", h.batchImportDishes)
+	g.POST("/dish-ingredients", h.batchImportDishIngredients)
+	g.POST("/customers", h.batchImportCustomers)
+	g.POST("/employees", h.batchImportEmployees)
+}
+
+// bindImportRows reads the rows of a batch import from a JSON array or from a
+// CSV file uploaded as "file" with the given columns. A header row is skipped
+// and missing trailing columns are empty. Rows that fail validation or the
+// database are logged to import_errors by the repository; a CSV value that
+// cannot be parsed rejects the whole file.
+func bindImportRows[T any](c *gin.Context, columns []string, parse func(rec []string) (T, error)) ([]T, bool) {
+	var rows []T
+	if strings.Contains(c.GetHeader("Content-Type"), "application/json") {
+		if err := c.ShouldBindJSON(&rows); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		return rows, true
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provide JSON array or multipart file"})
+		return nil, false
+	}
+	f, err := file.Open()
+	if err != nil {
+		writeError(c, err)
+		return nil, false
+	}
+	defer f.Close()
+	reader := csv.NewReader(f)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	for line := 1; ; line++ {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		if line == 1 && strings.EqualFold(rec[0], columns[0]) {
+			continue
+		}
+		for len(rec) < len(columns) {
+			rec = append(rec, "")
+		}
+		row, err := parse(rec)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("line %d: %v", line, err)})
+			return nil, false
+		}
+		rows = append(rows, row)
+	}
+	return rows, true
+}
+
+func writeImportResult(c *gin.Context, res domain.ImportResult, err error) {
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func csvInt(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(v)
+}
+
+func csvFloat(v string) (float64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(v, 64)
+}
+
+// csvBool parses a flag column; an empty value means true.
+func csvBool(v string) (bool, error) {
+	if v == "" {
+		return true, nil
+	}
+	return strconv.ParseBool(v)
+}
+
+func csvOptional(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
 }
 
 // batchImportProducts godoc
 // @Summary Batch import products from JSON array or CSV (name,unit,cost_price,is_available)
+// @Description Each row is imported behind its own savepoint: failed rows are logged to import_errors and the rest are committed.
 // @Tags batch-import
 // @Accept json
 // @Produce json
-// @Success 200 {object} map[string]int
+// @Param products body []domain.Product false "products"
+// @Success 200 {object} domain.ImportResult
+// @Security BearerAuth
 // @Router /batch-import/products [post]
 func (h *Handler) batchImportProducts(c *gin.Context) {
-	var products []domain.Product
-	ct := c.GetHeader("Content-Type")
-	switch {
-	case strings.Contains(ct, "application/json"):
-		if err := c.ShouldBindJSON(&products); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
+	products, ok := bindImportRows(c, []string{"name", "unit", "cost_price", "is_available"}, func(rec []string) (domain.Product, error) {
+		p := domain.Product{Name: rec[0], Unit: rec[1]}
+		if v, err := strconv.ParseFloat(rec[2], 64); err == nil {
+			p.CostPrice = &v
 		}
-	default:
-		file, err := c.FormFile("file")
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "provide JSON array or multipart file"})
-			return
+		p.IsAvailable = strings.ToLower(rec[3]) == "true"
+		return p, nil
+	})
+	if !ok {
+		return
+	}
+	res, err := h.Repo.BatchImportProducts(c.Request.Context(), products)
+	writeImportResult(c, res, err)
+}
+
+// batchImportMenuCategories godoc
+// @Summary Batch import menu categories from JSON array or CSV (name,description,sort_order,is_active)
+// @Description Rows are matched by name.
+// @Tags batch-import
+// @Accept json
+// @Produce json
+// @Param categories body []domain.MenuCategoryImport false "menu categories"
+// @Success 200 {object} domain.ImportResult
+// @Security BearerAuth
+// @Router /batch-import/menu-categories [post]
+func (h *Handler) batchImportMenuCategories(c *gin.Context) {
+	categories, ok := bindImportRows(c, []string{"name", "description", "sort_order", "is_active"}, func(rec []string) (domain.MenuCategoryImport, error) {
+		mc := domain.MenuCategoryImport{Name: rec[0], Description: rec[1]}
+		var err error
+		if mc.SortOrder, err = csvInt(rec[2]); err != nil {
+			return mc, err
 		}
-		f, err := file.Open()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+		active, err := csvBool(rec[3])
+		mc.IsActive = &active
+		return mc, err
+	})
+	if !ok {
+		return
+	}
+	res, err := h.Repo.BatchImportMenuCategories(c.Request.Context(), categories)
+	writeImportResult(c, res, err)
+}
+
+// batchImportTables godoc
+// @Summary Batch import restaurant tables from JSON array or CSV (table_number,seats,is_active,description)
+// @Description Rows are matched by table_number.
+// @Tags batch-import
+// @Accept json
+// @Produce json
+// @Param tables body []domain.TableImport false "tables"
+// @Success 200 {object} domain.ImportResult
+// @Security BearerAuth
+// @Router /batch-import/tables [post]
+func (h *Handler) batchImportTables(c *gin.Context) {
+	tables, ok := bindImportRows(c, []string{"table_number", "seats", "is_active", "description"}, func(rec []string) (domain.TableImport, error) {
+		t := domain.TableImport{Description: rec[3]}
+		var err error
+		if t.TableNumber, err = csvInt(rec[0]); err != nil {
+			return t, err
 		}
-		defer f.Close()
-		reader := csv.NewReader(f)
-		reader.TrimLeadingSpace = true
-		for {
-			rec, err := reader.Read()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
-			if len(rec) < 4 {
-				continue
-			}
-			p := domain.Product{
-				Name: rec[0],
-				Unit: rec[1],
-			}
-			if v, err := strconv.ParseFloat(rec[2], 64); err == nil {
-				p.CostPrice = &v
-			}
-			p.IsAvailable = strings.ToLower(rec[3]) == "true"
-			products = append(products, p)
+		if t.Seats, err = csvInt(rec[1]); err != nil {
+			return t, err
 		}
+		active, err := csvBool(rec[2])
+		t.IsActive = &active
+		return t, err
+	})
+	if !ok {
+		return
 	}
+	res, err := h.Repo.BatchImportTables(c.Request.Context(), tables)
+	writeImportResult(c, res, err)
+}
 
-	inserted, err := h.Repo.BatchImportProducts(c.Request.Context(), products)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+// batchImportDishes godoc
+// @Summary Batch import dishes from JSON array or CSV (category,name,price,cook_time_minutes,is_active,description)
+// @Description Rows are matched by category name and dish name.
+// @Tags batch-import
+// @Accept json
+// @Produce json
+// @Param dishes body []domain.DishImport false "dishes"
+// @Success 200 {object} domain.ImportResult
+// @Security BearerAuth
+// @Router /batch-import/dishes [post]
+func (h *Handler) batchImportDishes(c *gin.Context) {
+	dishes, ok := bindImportRows(c, []string{"category", "name", "price", "cook_time_minutes", "is_active", "description"}, func(rec []string) (domain.DishImport, error) {
+		d := domain.DishImport{Category: rec[0], Name: rec[1], Description: rec[5]}
+		var err error
+		if d.Price, err = csvFloat(rec[2]); err != nil {
+			return d, err
+		}
+		if d.CookTimeMinutes, err = csvInt(rec[3]); err != nil {
+			return d, err
+		}
+		active, err := csvBool(rec[4])
+		d.IsActive = &active
+		return d, err
+	})
+	if !ok {
+		return
+	}
+	res, err := h.Repo.BatchImportDishes(c.Request.Context(), dishes)
+	writeImportResult(c, res, err)
+}
+
+// batchImportDishIngredients godoc
+// @Summary Batch import recipes from JSON array or CSV (category,dish,product,quantity)
+// @Description Dishes are found by name within the category (which may be empty when the dish name is unique), products by name.
+// @Tags batch-import
+// @Accept json
+// @Produce json
+// @Param ingredients body []domain.DishIngredientImport false "recipe lines"
+// @Success 200 {object} domain.ImportResult
+// @Security BearerAuth
+// @Router /batch-import/dish-ingredients [post]
+func (h *Handler) batchImportDishIngredients(c *gin.Context) {
+	ingredients, ok := bindImportRows(c, []string{"category", "dish", "product", "quantity"}, func(rec []string) (domain.DishIngredientImport, error) {
+		di := domain.DishIngredientImport{Category: rec[0], Dish: rec[1], Product: rec[2]}
+		var err error
+		di.Quantity, err = csvFloat(rec[3])
+		return di, err
+	})
+	if !ok {
+		return
+	}
+	res, err := h.Repo.BatchImportDishIngredients(c.Request.Context(), ingredients)
+	writeImportResult(c, res, err)
+}
+
+// batchImportCustomers godoc
+// @Summary Batch import customers from JSON array or CSV (full_name,phone,email)
+// @Description Rows are matched by phone number in any format. vip_level is ignored: the loyalty engine sets it from payments.
+// @Tags batch-import
+// @Accept json
+// @Produce json
+// @Param customers body []domain.Customer false "customers"
+// @Success 200 {object} domain.ImportResult
+// @Security BearerAuth
+// @Router /batch-import/customers [post]
+func (h *Handler) batchImportCustomers(c *gin.Context) {
+	customers, ok := bindImportRows(c, []string{"full_name", "phone", "email"}, func(rec []string) (domain.Customer, error) {
+		return domain.Customer{FullName: rec[0], Phone: rec[1], Email: csvOptional(rec[2])}, nil
+	})
+	if !ok {
+		return
+	}
+	res, err := h.Repo.BatchImportCustomers(c.Request.Context(), customers)
+	writeImportResult(c, res, err)
+}
+
+// batchImportEmployees godoc
+// @Summary Batch import employees from JSON array or CSV (full_name,phone,email,role,hired_at,is_active)
+// @Description Rows are matched by phone, roles by name. Requires employees:create.
+// @Tags batch-import
+// @Accept json
+// @Produce json
+// @Param employees body []domain.EmployeeImport false "employees"
+// @Success 200 {object} domain.ImportResult
+// @Security BearerAuth
+// @Router /batch-import/employees [post]
+func (h *Handler) batchImportEmployees(c *gin.Context) {
+	employees, ok := bindImportRows(c, []string{"full_name", "phone", "email", "role", "hired_at", "is_active"}, func(rec []string) (domain.EmployeeImport, error) {
+		e := domain.EmployeeImport{FullName: rec[0], Phone: rec[1], Email: csvOptional(rec[2]), Role: rec[3], HiredAt: rec[4]}
+		active, err := csvBool(rec[5])
+		e.IsActive = &active
+		return e, err
+	})
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"inserted": inserted, "total": len(products)})
+	res, err := h.Repo.BatchImportEmployees(c.Request.Context(), employees)
+	writeImportResult(c, res, err)
 }
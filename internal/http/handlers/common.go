@@ -1,10 +1,16 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+
 	"github.com/example/rms/internal/repository"
 )
 
@@ -21,3 +27,130 @@ func parseID(c *gin.Context, param string) (int64, bool) {
 	}
 	return id, true
 }
+
+// parseListQuery reads the shared list parameters: sort (prefix "-" for
+// descending), cursor, limit and filters written as field=value for eq or
+// field[op]=value for in, gte and lte; in takes comma-separated values.
+func parseListQuery(c *gin.Context) repository.ListQuery {
+	q := repository.ListQuery{Sort: c.Query("sort"), Cursor: c.Query("cursor")}
+	q.Limit, _ = strconv.Atoi(c.Query("limit"))
+	for key, values := range c.Request.URL.Query() {
+		if key == "sort" || key == "cursor" || key == "limit" {
+			continue
+		}
+		field, op := key, "eq"
+		if i := strings.IndexByte(key, '['); i > 0 && strings.HasSuffix(key, "]") {
+			field, op = key[:i], key[i+1:len(key)-1]
+		}
+		for _, v := range values {
+			f := repository.ListFilter{Field: field, Op: op, Values: []string{v}}
+			if op == "in" {
+				f.Values = strings.Split(v, ",")
+			}
+			q.Filters = append(q.Filters, f)
+		}
+	}
+	// Map iteration order is random; keep the generated SQL stable.
+	sort.Slice(q.Filters, func(i, j int) bool {
+		if q.Filters[i].Field != q.Filters[j].Field {
+			return q.Filters[i].Field < q.Filters[j].Field
+		}
+		return q.Filters[i].Op < q.Filters[j].Op
+	})
+	return q
+}
+
+// errorStatuses maps repository domain errors to HTTP statuses.
+var errorStatuses = []struct {
+	err    error
+	status int
+}{
+	{repository.ErrInvalidStatus, http.StatusBadRequest},
+	{repository.ErrInvalidRecord, http.StatusBadRequest},
+	{repository.ErrInvalidCursor, http.StatusBadRequest},
+	{repository.ErrInvalidListQuery, http.StatusBadRequest},
+	{repository.ErrInvalidPermission, http.StatusBadRequest},
+	{repository.ErrWeakCredentials, http.StatusBadRequest},
+	{repository.ErrNotAudited, http.StatusBadRequest},
+	{repository.ErrInvalidCredentials, http.StatusUnauthorized},
+	{repository.ErrUnauthenticated, http.StatusUnauthorized},
+	{repository.ErrPermissionDenied, http.StatusForbidden},
+	{repository.ErrSystemRole, http.StatusForbidden},
+	{repository.ErrOverrideForbidden, http.StatusForbidden},
+	{repository.ErrRefundForbidden, http.StatusForbidden},
+	{repository.ErrShiftAlreadyOpen, http.StatusConflict},
+	{repository.ErrShiftAlreadyClosed, http.StatusConflict},
+	{repository.ErrNoOpenShift, http.StatusConflict},
+	{repository.ErrInsufficientStock, http.StatusConflict},
+	{repository.ErrIllegalTransition, http.StatusConflict},
+	{repository.ErrReservationOverlap, http.StatusConflict},
+	{repository.ErrOrderFinalized, http.StatusConflict},
+	{repository.ErrRestoreConflict, http.StatusConflict},
+	{repository.ErrLoginLocked, http.StatusTooManyRequests},
+	{repository.ErrDishUnavailable, http.StatusUnprocessableEntity},
+	{repository.ErrInvalidSplit, http.StatusUnprocessableEntity},
+	{repository.ErrInvalidRefund, http.StatusUnprocessableEntity},
+	{repository.ErrPaymentExceedsDue, http.StatusUnprocessableEntity},
+	{repository.ErrInvalidRestore, http.StatusUnprocessableEntity},
+	{repository.ErrImportFailed, http.StatusUnprocessableEntity},
+}
+
+// constraintErrors maps PostgreSQL integrity violation and invalid input codes to HTTP statuses and stable error kinds.
+var constraintErrors = map[pq.ErrorCode]struct {
+	status int
+	kind   string
+}{
+	"23P01": {http.StatusConflict, "exclusion_violation"},
+	"23505": {http.StatusConflict, "unique_violation"},
+	"23503": {http.StatusUnprocessableEntity, "foreign_key_violation"},
+	"23514": {http.StatusUnprocessableEntity, "check_violation"},
+	// Malformed filter values are rejected by PostgreSQL casts.
+	"22P02": {http.StatusBadRequest, "invalid_input"},
+	"22007": {http.StatusBadRequest, "invalid_input"},
+	"22008": {http.StatusBadRequest, "invalid_input"},
+}
+
+// writeError renders err with the status that matches it: domain errors and
+// constraint violations become 4xx responses, everything else is a 500.
+func writeError(c *gin.Context, err error) {
+	var conflict *repository.ReservationConflictError
+	if errors.As(err, &conflict) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":                   err.Error(),
+			"kind":                    "reservation_overlap",
+			"conflicting_reservation": conflict.Conflicting,
+		})
+		return
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "record not found"})
+		return
+	}
+	for _, e := range errorStatuses {
+		if errors.Is(err, e.err) {
+			c.JSON(e.status, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if ce, ok := constraintErrors[pqErr.Code]; ok {
+			body := gin.H{"error": pqErr.Message, "kind": ce.kind}
+			if pqErr.Constraint != "" {
+				body["constraint"] = pqErr.Constraint
+			}
+			if pqErr.Table != "" {
+				body["table"] = pqErr.Table
+			}
+			if pqErr.Detail != "" {
+				body["detail"] = pqErr.Detail
+			}
+			c.JSON(ce.status, body)
+			return
+		}
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
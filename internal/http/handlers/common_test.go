@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/rms/internal/repository"
+)
+
+func TestParseListQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name  string
+		query string
+		want  repository.ListQuery
+	}{
+		{
+			name:  "no parameters",
+			query: "",
+			want:  repository.ListQuery{},
+		},
+		{
+			name:  "paging parameters are not filters",
+			query: "sort=-created_at&cursor=abc&limit=20",
+			want:  repository.ListQuery{Sort: "-created_at", Cursor: "abc", Limit: 20},
+		},
+		{
+			name:  "invalid limit falls back to zero",
+			query: "limit=many",
+			want:  repository.ListQuery{},
+		},
+		{
+			name:  "plain field is eq",
+			query: "status=new",
+			want: repository.ListQuery{Filters: []repository.ListFilter{
+				{Field: "status", Op: "eq", Values: []string{"new"}},
+			}},
+		},
+		{
+			name:  "in splits on commas",
+			query: "status[in]=new,in_progress",
+			want: repository.ListQuery{Filters: []repository.ListFilter{
+				{Field: "status", Op: "in", Values: []string{"new", "in_progress"}},
+			}},
+		},
+		{
+			name:  "filters are sorted by field and operator",
+			query: "total[lte]=500&created_at[gte]=2024-03-01&total[gte]=100",
+			want: repository.ListQuery{Filters: []repository.ListFilter{
+				{Field: "created_at", Op: "gte", Values: []string{"2024-03-01"}},
+				{Field: "total", Op: "gte", Values: []string{"100"}},
+				{Field: "total", Op: "lte", Values: []string{"500"}},
+			}},
+		},
+		{
+			name:  "repeated parameter gives one filter per value",
+			query: "table_id=1&table_id=2",
+			want: repository.ListQuery{Filters: []repository.ListFilter{
+				{Field: "table_id", Op: "eq", Values: []string{"1"}},
+				{Field: "table_id", Op: "eq", Values: []string{"2"}},
+			}},
+		},
+		{
+			name:  "unclosed bracket is a field name",
+			query: "status[in=new",
+			want: repository.ListQuery{Filters: []repository.ListFilter{
+				{Field: "status[in", Op: "eq", Values: []string{"new"}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/api/orders?"+tt.query, nil)
+			if got := parseListQuery(c); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseListQuery(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
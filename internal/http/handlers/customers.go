@@ -10,26 +10,60 @@ import (
 
 // RegisterCustomers registers customer endpoints.
 func RegisterCustomers(r *gin.RouterGroup, h *Handler) {
-	g := r.Group("/customers")
+	g := r.Group("/customers", h.authorize("customers"))
 	g.GET("", h.listCustomers)
 	g.POST("", h.createCustomer)
+	g.GET("/:id", h.getCustomer)
 	g.PUT("/:id", h.updateCustomer)
 	g.DELETE("/:id", h.deleteCustomer)
 }
 
 // listCustomers godoc
-// @Summary List customers
+// @Summary Search customers
+// @Description Newest first by default; sortable by id, full_name, vip_level, created_at. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.
 // @Tags customers
 // @Produce json
-// @Success 200 {array} domain.Customer
+// @Param phone query string false "phone prefix in any format, e.g. +7 (999) 123 or 8999123"
+// @Param name query string false "case-insensitive name substring"
+// @Param email query string false "email"
+// @Param vip_level query int false "VIP level"
+// @Param created_at[gte] query string false "created from"
+// @Param sort query string false "sort field, prefix - for descending"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "page size, up to 200"
+// @Success 200 {object} domain.Page{items=[]domain.Customer}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
 // @Router /customers [get]
 func (h *Handler) listCustomers(c *gin.Context) {
-	customers, err := h.Repo.ListCustomers(c.Request.Context())
+	page, err := h.Repo.ListCustomers(c.Request.Context(), parseListQuery(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, customers)
+	c.JSON(http.StatusOK, page)
+}
+
+// getCustomer godoc
+// @Summary Get customer profile with loyalty, visits, reservations, orders and favourite dishes
+// @Tags customers
+// @Produce json
+// @Param id path int true "customer id"
+// @Success 200 {object} domain.CustomerDetails
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /customers/{id} [get]
+func (h *Handler) getCustomer(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	customer, err := h.Repo.GetCustomerDetails(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, customer)
 }
 
 // createCustomer godoc
@@ -39,6 +73,7 @@ func (h *Handler) listCustomers(c *gin.Context) {
 // @Produce json
 // @Param customer body domain.Customer true "customer"
 // @Success 201 {object} domain.Customer
+// @Security BearerAuth
 // @Router /customers [post]
 func (h *Handler) createCustomer(c *gin.Context) {
 	var req domain.Customer
@@ -51,7 +86,7 @@ func (h *Handler) createCustomer(c *gin.Context) {
 		return
 	}
 	if err := h.Repo.CreateCustomer(c.Request.Context(), &req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, req)
@@ -65,6 +100,7 @@ func (h *Handler) createCustomer(c *gin.Context) {
 // @Param id path int true "customer id"
 // @Param customer body domain.Customer true "customer"
 // @Success 200 {object} domain.Customer
+// @Security BearerAuth
 // @Router /customers/{id} [put]
 func (h *Handler) updateCustomer(c *gin.Context) {
 	id, ok := parseID(c, "id")
@@ -77,7 +113,7 @@ func (h *Handler) updateCustomer(c *gin.Context) {
 		return
 	}
 	if err := h.Repo.UpdateCustomer(c.Request.Context(), id, &req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	req.ID = id
@@ -89,6 +125,7 @@ func (h *Handler) updateCustomer(c *gin.Context) {
 // @Tags customers
 // @Param id path int true "customer id"
 // @Success 204
+// @Security BearerAuth
 // @Router /customers/{id} [delete]
 func (h *Handler) deleteCustomer(c *gin.Context) {
 	id, ok := parseID(c, "id")
@@ -96,7 +133,7 @@ func (h *Handler) deleteCustomer(c *gin.Context) {
 		return
 	}
 	if err := h.Repo.DeleteCustomer(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
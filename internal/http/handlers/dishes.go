@@ -1,8 +1,8 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -11,28 +11,40 @@ import (
 
 // RegisterDishes registers dishes endpoints.
 func RegisterDishes(r *gin.RouterGroup, h *Handler) {
-	g := r.Group("/dishes")
+	g := r.Group("/dishes", h.authorize("dishes"))
 	g.GET("", h.listDishes)
 	g.POST("", h.upsertDish)
 	g.PUT("/:id", h.upsertDish)
 	g.DELETE("/:id", h.deleteDish)
+	g.GET("/:id/ingredients", h.listDishIngredients)
+	g.POST("/:id/ingredients", h.addDishIngredient)
+	g.PUT("/:id/ingredients", h.replaceDishIngredients)
+	g.PUT("/:id/ingredients/:productId", h.updateDishIngredient)
+	g.DELETE("/:id/ingredients/:productId", h.deleteDishIngredient)
 }
 
 // listDishes godoc
 // @Summary List dishes
+// @Description Sortable by id, name, price. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.
 // @Tags dishes
 // @Produce json
-// @Param limit query int false "limit"
-// @Success 200 {array} domain.Dish
+// @Param category_id query int false "category id"
+// @Param is_active query bool false "active flag"
+// @Param price[lte] query number false "maximum price"
+// @Param sort query string false "sort field, prefix - for descending"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "page size, up to 200"
+// @Success 200 {object} domain.Page{items=[]domain.Dish}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
 // @Router /dishes [get]
 func (h *Handler) listDishes(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
-	dishes, err := h.Repo.ListDishes(c.Request.Context(), limit)
+	page, err := h.Repo.ListDishes(c.Request.Context(), parseListQuery(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, dishes)
+	c.JSON(http.StatusOK, page)
 }
 
 // upsertDish godoc
@@ -42,6 +54,7 @@ func (h *Handler) listDishes(c *gin.Context) {
 // @Produce json
 // @Param dish body domain.Dish true "dish"
 // @Success 200 {object} domain.Dish
+// @Security BearerAuth
 // @Router /dishes [post]
 func (h *Handler) upsertDish(c *gin.Context) {
 	var req domain.Dish
@@ -49,12 +62,8 @@ func (h *Handler) upsertDish(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if req.Name == "" || req.CategoryID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "name and category_id are required"})
-		return
-	}
 	if err := h.Repo.UpsertDish(c.Request.Context(), &req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, req)
@@ -65,6 +74,7 @@ func (h *Handler) upsertDish(c *gin.Context) {
 // @Tags dishes
 // @Param id path int true "dish id"
 // @Success 204
+// @Security BearerAuth
 // @Router /dishes/{id} [delete]
 func (h *Handler) deleteDish(c *gin.Context) {
 	id, ok := parseID(c, "id")
@@ -72,8 +82,164 @@ func (h *Handler) deleteDish(c *gin.Context) {
 		return
 	}
 	if err := h.Repo.DeleteDish(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
+
+// listDishIngredients godoc
+// @Summary List dish recipe
+// @Tags dishes
+// @Produce json
+// @Param id path int true "dish id"
+// @Success 200 {array} domain.DishIngredient
+// @Security BearerAuth
+// @Router /dishes/{id}/ingredients [get]
+func (h *Handler) listDishIngredients(c *gin.Context) {
+	dishID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	items, err := h.Repo.ListDishIngredients(c.Request.Context(), dishID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// addDishIngredient godoc
+// @Summary Add or update ingredient in dish recipe
+// @Tags dishes
+// @Accept json
+// @Produce json
+// @Param id path int true "dish id"
+// @Param ingredient body domain.DishIngredient true "ingredient"
+// @Success 201 {object} domain.DishIngredient
+// @Security BearerAuth
+// @Router /dishes/{id}/ingredients [post]
+func (h *Handler) addDishIngredient(c *gin.Context) {
+	dishID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var req domain.DishIngredient
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.DishID = dishID
+	if err := h.Repo.AddDishIngredient(c.Request.Context(), &req); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, req)
+}
+
+type ingredientQuantityRequest struct {
+	Quantity float64 `json:"quantity"`
+}
+
+// updateDishIngredient godoc
+// @Summary Update ingredient quantity in dish recipe
+// @Tags dishes
+// @Accept json
+// @Param id path int true "dish id"
+// @Param productId path int true "product id"
+// @Param ingredient body ingredientQuantityRequest true "quantity"
+// @Success 200
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /dishes/{id}/ingredients/{productId} [put]
+func (h *Handler) updateDishIngredient(c *gin.Context) {
+	dishID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	productID, ok := parseID(c, "productId")
+	if !ok {
+		return
+	}
+	var req ingredientQuantityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Quantity <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quantity must be positive"})
+		return
+	}
+	if err := h.Repo.UpdateDishIngredientQuantity(c.Request.Context(), dishID, productID, req.Quantity); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// deleteDishIngredient godoc
+// @Summary Remove ingredient from dish recipe
+// @Tags dishes
+// @Param id path int true "dish id"
+// @Param productId path int true "product id"
+// @Success 204
+// @Security BearerAuth
+// @Router /dishes/{id}/ingredients/{productId} [delete]
+func (h *Handler) deleteDishIngredient(c *gin.Context) {
+	dishID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	productID, ok := parseID(c, "productId")
+	if !ok {
+		return
+	}
+	if err := h.Repo.DeleteDishIngredient(c.Request.Context(), dishID, productID); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// replaceDishIngredients godoc
+// @Summary Replace the whole dish recipe in one transaction
+// @Tags dishes
+// @Accept json
+// @Produce json
+// @Param id path int true "dish id"
+// @Param ingredients body []domain.DishIngredient true "new recipe"
+// @Success 200 {array} domain.DishIngredient
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /dishes/{id}/ingredients [put]
+func (h *Handler) replaceDishIngredients(c *gin.Context) {
+	dishID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var req []domain.DishIngredient
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	seen := make(map[int64]bool, len(req))
+	for _, item := range req {
+		if item.ProductID == 0 || item.Quantity <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "each ingredient needs product_id and positive quantity"})
+			return
+		}
+		if seen[item.ProductID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("product %d is listed twice", item.ProductID)})
+			return
+		}
+		seen[item.ProductID] = true
+	}
+	if err := h.Repo.ReplaceDishIngredients(c.Request.Context(), dishID, req); err != nil {
+		writeError(c, err)
+		return
+	}
+	if req == nil {
+		req = []domain.DishIngredient{}
+	}
+	c.JSON(http.StatusOK, req)
+}
@@ -10,26 +10,36 @@ import (
 
 // RegisterEmployees registers employee endpoints.
 func RegisterEmployees(r *gin.RouterGroup, h *Handler) {
-	g := r.Group("/employees")
+	g := r.Group("/employees", h.authorize("employees"))
 	g.GET("", h.listEmployees)
 	g.POST("", h.createEmployee)
 	g.PUT("/:id", h.updateEmployee)
 	g.DELETE("/:id", h.deleteEmployee)
+	g.PUT("/:id/credentials", h.setEmployeeCredentials)
 }
 
 // listEmployees godoc
 // @Summary List employees
+// @Description Sortable by id, full_name, hired_at. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.
 // @Tags employees
 // @Produce json
-// @Success 200 {array} domain.Employee
+// @Param role_id query int false "role id"
+// @Param is_active query bool false "active flag"
+// @Param hired_at[gte] query string false "hired from (date)"
+// @Param sort query string false "sort field, prefix - for descending"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "page size, up to 200"
+// @Success 200 {object} domain.Page{items=[]domain.Employee}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
 // @Router /employees [get]
 func (h *Handler) listEmployees(c *gin.Context) {
-	employees, err := h.Repo.ListEmployees(c.Request.Context())
+	page, err := h.Repo.ListEmployees(c.Request.Context(), parseListQuery(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, employees)
+	c.JSON(http.StatusOK, page)
 }
 
 // createEmployee godoc
@@ -39,6 +49,7 @@ func (h *Handler) listEmployees(c *gin.Context) {
 // @Produce json
 // @Param employee body domain.Employee true "employee"
 // @Success 201 {object} domain.Employee
+// @Security BearerAuth
 // @Router /employees [post]
 func (h *Handler) createEmployee(c *gin.Context) {
 	var req domain.Employee
@@ -51,7 +62,7 @@ func (h *Handler) createEmployee(c *gin.Context) {
 		return
 	}
 	if err := h.Repo.CreateEmployee(c.Request.Context(), &req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, req)
@@ -65,6 +76,7 @@ func (h *Handler) createEmployee(c *gin.Context) {
 // @Param id path int true "employee id"
 // @Param employee body domain.Employee true "employee"
 // @Success 200 {object} domain.Employee
+// @Security BearerAuth
 // @Router /employees/{id} [put]
 func (h *Handler) updateEmployee(c *gin.Context) {
 	id, ok := parseID(c, "id")
@@ -77,7 +89,7 @@ func (h *Handler) updateEmployee(c *gin.Context) {
 		return
 	}
 	if err := h.Repo.UpdateEmployee(c.Request.Context(), id, &req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	req.ID = id
@@ -89,6 +101,7 @@ func (h *Handler) updateEmployee(c *gin.Context) {
 // @Tags employees
 // @Param id path int true "employee id"
 // @Success 204
+// @Security BearerAuth
 // @Router /employees/{id} [delete]
 func (h *Handler) deleteEmployee(c *gin.Context) {
 	id, ok := parseID(c, "id")
@@ -96,7 +109,7 @@ func (h *Handler) deleteEmployee(c *gin.Context) {
 		return
 	}
 	if err := h.Repo.DeleteEmployee(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
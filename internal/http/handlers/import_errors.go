@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterImportErrors registers endpoints for reviewing failed import rows.
+func RegisterImportErrors(r *gin.RouterGroup, h *Handler) {
+	g := r.Group("/import-errors", h.authorize("import"))
+	g.GET("", h.listImportErrors)
+	g.POST("/:id/retry", h.retryImportError)
+	g.POST("/dismiss", h.dismissImportErrors)
+}
+
+// listImportErrors godoc
+// @Summary List failed import rows
+// @Description Newest first by default; sortable by id, created_at. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.
+// @Tags batch-import
+// @Produce json
+// @Param entity query string false "entity, e.g. product"
+// @Param status query string false "open, resolved or dismissed"
+// @Param created_at[gte] query string false "created from (timestamp)"
+// @Param created_at[lte] query string false "created until (timestamp)"
+// @Param sort query string false "sort field, prefix - for descending"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "page size, up to 200"
+// @Success 200 {object} domain.Page{items=[]domain.ImportError}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /import-errors [get]
+func (h *Handler) listImportErrors(c *gin.Context) {
+	page, err := h.Repo.ListImportErrors(c.Request.Context(), parseListQuery(c))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+type retryImportRequest struct {
+	// RawData replaces the stored row before the retry; omit it to retry as is.
+	RawData json.RawMessage `json:"raw_data" swaggertype:"object"`
+}
+
+// retryImportError godoc
+// @Summary Retry a failed import row
+// @Description Re-runs the row through its import path, optionally with corrected raw_data. On success the row is marked resolved; on failure it keeps the new data and error and 422 is returned.
+// @Tags batch-import
+// @Accept json
+// @Produce json
+// @Param id path int true "import error id"
+// @Param retry body retryImportRequest false "corrected row"
+// @Success 200 {object} domain.ImportError
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /import-errors/{id}/retry [post]
+func (h *Handler) retryImportError(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var req retryImportRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	row, err := h.Repo.RetryImportError(c.Request.Context(), id, req.RawData)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, row)
+}
+
+type dismissImportRequest struct {
+	IDs           []int64    `json:"ids"`
+	Entity        string     `json:"entity"`
+	CreatedBefore *time.Time `json:"created_before"`
+}
+
+// dismissImportErrors godoc
+// @Summary Dismiss failed import rows in bulk
+// @Description Dismisses the open rows listed in ids, or, without ids, every open row of entity and/or created before created_before.
+// @Tags batch-import
+// @Accept json
+// @Produce json
+// @Param dismiss body dismissImportRequest true "rows to dismiss"
+// @Success 200 {object} map[string]int
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /import-errors/dismiss [post]
+func (h *Handler) dismissImportErrors(c *gin.Context) {
+	var req dismissImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	n, err := h.Repo.DismissImportErrors(c.Request.Context(), req.IDs, req.Entity, req.CreatedBefore)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"dismissed": n})
+}
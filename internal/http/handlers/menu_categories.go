@@ -10,7 +10,7 @@ import (
 
 // RegisterMenuCategories registers menu categories endpoints.
 func RegisterMenuCategories(r *gin.RouterGroup, h *Handler) {
-	g := r.Group("/menu-categories")
+	g := r.Group("/menu-categories", h.authorize("menu"))
 	g.GET("", h.listMenuCategories)
 	g.POST("", h.upsertMenuCategory)
 	g.PUT("/:id", h.upsertMenuCategory)
@@ -22,11 +22,12 @@ func RegisterMenuCategories(r *gin.RouterGroup, h *Handler) {
 // @Tags menu-categories
 // @Produce json
 // @Success 200 {array} domain.MenuCategory
+// @Security BearerAuth
 // @Router /menu-categories [get]
 func (h *Handler) listMenuCategories(c *gin.Context) {
 	cats, err := h.Repo.ListMenuCategories(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, cats)
@@ -39,6 +40,7 @@ func (h *Handler) listMenuCategories(c *gin.Context) {
 // @Produce json
 // @Param category body domain.MenuCategory true "category"
 // @Success 200 {object} domain.MenuCategory
+// @Security BearerAuth
 // @Router /menu-categories [post]
 func (h *Handler) upsertMenuCategory(c *gin.Context) {
 	var req domain.MenuCategory
@@ -51,7 +53,7 @@ func (h *Handler) upsertMenuCategory(c *gin.Context) {
 		return
 	}
 	if err := h.Repo.UpsertMenuCategory(c.Request.Context(), &req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, req)
@@ -62,6 +64,7 @@ func (h *Handler) upsertMenuCategory(c *gin.Context) {
 // @Tags menu-categories
 // @Param id path int true "category id"
 // @Success 204
+// @Security BearerAuth
 // @Router /menu-categories/{id} [delete]
 func (h *Handler) deleteMenuCategory(c *gin.Context) {
 	id, ok := parseID(c, "id")
@@ -69,7 +72,7 @@ func (h *Handler) deleteMenuCategory(c *gin.Context) {
 		return
 	}
 	if err := h.Repo.DeleteMenuCategory(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -11,32 +10,45 @@ import (
 
 // RegisterOrders registers order endpoints.
 func RegisterOrders(r *gin.RouterGroup, h *Handler) {
-	g := r.Group("/orders")
+	g := r.Group("/orders", h.authorize("orders"))
 	g.GET("", h.listOrders)
 	g.POST("", h.createOrder)
+	g.GET("/:id", h.getOrder)
 	g.PUT("/:id/status", h.updateOrderStatus)
+	g.PUT("/:id/pricing", h.setOrderPricing)
 	g.GET("/:id/items", h.listOrderItems)
 	g.POST("/:id/items", h.addOrderItem)
 	g.DELETE("/:id/items/:itemId", h.deleteOrderItem)
+	g.GET("/:id/payments", h.getOrderBalance)
+	g.POST("/:id/split", h.splitOrderBill)
+	g.GET("/:id/history", h.getOrderHistory)
 }
 
 // listOrders godoc
 // @Summary List orders
+// @Description Newest first by default; sortable by id, created_at. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.
 // @Tags orders
 // @Produce json
-// @Param status query string false "status filter"
-// @Param limit query int false "limit"
-// @Success 200 {array} domain.Order
+// @Param status query string false "status"
+// @Param status[in] query string false "comma-separated statuses"
+// @Param waiter_id query int false "waiter id"
+// @Param shift_id query int false "shift id"
+// @Param created_at[gte] query string false "created from"
+// @Param created_at[lte] query string false "created until"
+// @Param sort query string false "sort field, prefix - for descending"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "page size, up to 200"
+// @Success 200 {object} domain.Page{items=[]domain.Order}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
 // @Router /orders [get]
 func (h *Handler) listOrders(c *gin.Context) {
-	status := c.Query("status")
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
-	orders, err := h.Repo.ListOrders(c.Request.Context(), status, limit)
+	page, err := h.Repo.ListOrders(c.Request.Context(), parseListQuery(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, orders)
+	c.JSON(http.StatusOK, page)
 }
 
 type orderRequest struct {
@@ -51,11 +63,16 @@ type orderRequest struct {
 
 // createOrder godoc
 // @Summary Create order with items
+// @Description Without shift_id the order joins the open shift; a given shift_id must be an open shift (409 otherwise).
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param order body orderRequest true "order"
 // @Success 201 {object} domain.Order
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
 // @Router /orders [post]
 func (h *Handler) createOrder(c *gin.Context) {
 	var req orderRequest
@@ -78,19 +95,57 @@ func (h *Handler) createOrder(c *gin.Context) {
 	if order.Status == "" {
 		order.Status = "new"
 	}
+	if order.Status != "new" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "orders are created with status new"})
+		return
+	}
+	for _, item := range req.Items {
+		if item.DishID == 0 || item.Quantity <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "each item needs dish_id and quantity"})
+			return
+		}
+	}
 	if err := h.Repo.CreateOrder(c.Request.Context(), &order, req.Items); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, order)
 }
 
+// getOrder godoc
+// @Summary Get order with pricing, items and status history
+// @Tags orders
+// @Produce json
+// @Param id path int true "order id"
+// @Success 200 {object} domain.OrderDetails
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /orders/{id} [get]
+func (h *Handler) getOrder(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	order, err := h.Repo.GetOrderDetails(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
 // updateOrderStatus godoc
-// @Summary Update order status
+// @Summary Update order status, deducting or restoring ingredient stock
+// @Description Allowed transitions: new -> in_progress -> closed, new/in_progress -> cancelled.
 // @Tags orders
+// @Produce json
 // @Param id path int true "order id"
 // @Param status query string true "new status"
-// @Success 200
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
 // @Router /orders/{id}/status [put]
 func (h *Handler) updateOrderStatus(c *gin.Context) {
 	id, ok := parseID(c, "id")
@@ -102,11 +157,60 @@ func (h *Handler) updateOrderStatus(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "status is required"})
 		return
 	}
-	if err := h.Repo.UpdateOrderStatus(c.Request.Context(), id, status); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	warnings, err := h.Repo.UpdateOrderStatus(c.Request.Context(), id, status)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "status": status, "warnings": warnings})
+}
+
+type orderPricingRequest struct {
+	DiscountPercent      float64  `json:"discount_percent"`
+	ServiceChargePercent *float64 `json:"service_charge_percent"`
+}
+
+// orderPricingResponse is the new bill plus the warnings of closing the order
+// when its payments already cover it.
+type orderPricingResponse struct {
+	domain.OrderPricing
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// setOrderPricing godoc
+// @Summary Set order discount and service charge
+// @Description The caller must hold orders:discount; omitted service_charge_percent keeps the current rate. An order whose payments cover the new total is closed, with stock warnings as for a payment.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "order id"
+// @Param pricing body orderPricingRequest true "pricing"
+// @Success 200 {object} orderPricingResponse
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /orders/{id}/pricing [put]
+func (h *Handler) setOrderPricing(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var req orderPricingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.DiscountPercent < 0 || req.DiscountPercent > 100 ||
+		(req.ServiceChargePercent != nil && (*req.ServiceChargePercent < 0 || *req.ServiceChargePercent > 100)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "percentages must be between 0 and 100"})
+		return
+	}
+	pricing, warnings, err := h.Repo.SetOrderPricing(c.Request.Context(), id, req.DiscountPercent, req.ServiceChargePercent)
+	if err != nil {
+		writeError(c, err)
 		return
 	}
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, orderPricingResponse{OrderPricing: pricing, Warnings: warnings})
 }
 
 // listOrderItems godoc
@@ -115,6 +219,7 @@ func (h *Handler) updateOrderStatus(c *gin.Context) {
 // @Param id path int true "order id"
 // @Produce json
 // @Success 200 {array} domain.OrderItem
+// @Security BearerAuth
 // @Router /orders/{id}/items [get]
 func (h *Handler) listOrderItems(c *gin.Context) {
 	orderID, ok := parseID(c, "id")
@@ -123,20 +228,33 @@ func (h *Handler) listOrderItems(c *gin.Context) {
 	}
 	items, err := h.Repo.ListOrderItems(c.Request.Context(), orderID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, items)
 }
 
+// orderItemResponse is the stored item plus stock shortage warnings when the
+// order's ingredients were already deducted.
+type orderItemResponse struct {
+	domain.OrderItem
+	Warnings []string `json:"warnings,omitempty"`
+}
+
 // addOrderItem godoc
-// @Summary Add or update order item
+// @Summary Add or update order item priced from the menu
+// @Description price_at_moment and discount_percent are ignored unless price_override_reason is set; the caller must then hold orders:override_price and is recorded as price_override_by.
 // @Tags orders
 // @Param id path int true "order id"
 // @Accept json
 // @Produce json
 // @Param item body domain.OrderItem true "item"
-// @Success 200
+// @Success 200 {object} orderItemResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
 // @Router /orders/{id}/items [post]
 func (h *Handler) addOrderItem(c *gin.Context) {
 	orderID, ok := parseID(c, "id")
@@ -152,11 +270,12 @@ func (h *Handler) addOrderItem(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "dish_id and quantity are required"})
 		return
 	}
-	if err := h.Repo.AddOrderItem(c.Request.Context(), orderID, req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	warnings, err := h.Repo.AddOrderItem(c.Request.Context(), orderID, &req)
+	if err != nil {
+		writeError(c, err)
 		return
 	}
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, orderItemResponse{OrderItem: req, Warnings: warnings})
 }
 
 // deleteOrderItem godoc
@@ -165,14 +284,21 @@ func (h *Handler) addOrderItem(c *gin.Context) {
 // @Param id path int true "order id"
 // @Param itemId path int true "item id"
 // @Success 204
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
 // @Router /orders/{id}/items/{itemId} [delete]
 func (h *Handler) deleteOrderItem(c *gin.Context) {
+	orderID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
 	itemID, ok := parseID(c, "itemId")
 	if !ok {
 		return
 	}
-	if err := h.Repo.DeleteOrderItem(c.Request.Context(), itemID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.Repo.DeleteOrderItem(c.Request.Context(), orderID, itemID); err != nil {
+		writeError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
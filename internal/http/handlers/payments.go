@@ -11,27 +11,35 @@ import (
 
 // RegisterPayments registers payment endpoints.
 func RegisterPayments(r *gin.RouterGroup, h *Handler) {
-	g := r.Group("/payments")
-	g.POST("", h.upsertPayment)
-	g.DELETE("/:orderId", h.deletePayment)
+	g := r.Group("/payments", h.authorize("payments"))
+	g.POST("", h.createPayment)
+	g.POST("/:id/pay", h.payPayment)
+	g.DELETE("/:id", h.deletePayment)
+	g.GET("/:id/refunds", h.listRefunds)
+	g.POST("/:id/refunds", h.refundPayment)
+	g.GET("/:id/history", h.getPaymentHistory)
 }
 
-// upsertPayment godoc
-// @Summary Create or update payment
+// createPayment godoc
+// @Summary Add payment to order
+// @Description An order may have several payments; it is closed automatically once paid payments cover its total.
 // @Tags payments
 // @Accept json
 // @Produce json
 // @Param payment body domain.Payment true "payment"
-// @Success 200 {object} domain.Payment
+// @Success 201 {object} map[string]interface{}
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
 // @Router /payments [post]
-func (h *Handler) upsertPayment(c *gin.Context) {
+func (h *Handler) createPayment(c *gin.Context) {
 	var req domain.Payment
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if req.OrderID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "order_id is required"})
+	if req.OrderID == 0 || req.Method == "" || req.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order_id, method and positive amount are required"})
 		return
 	}
 	if req.PaidAt.IsZero() {
@@ -40,27 +48,184 @@ func (h *Handler) upsertPayment(c *gin.Context) {
 	if req.Status == "" {
 		req.Status = "paid"
 	}
-	if err := h.Repo.UpsertPayment(c.Request.Context(), &req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if req.Status != "paid" && req.Status != "pending" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be paid or pending"})
 		return
 	}
-	c.JSON(http.StatusOK, req)
+	warnings, err := h.Repo.CreatePayment(c.Request.Context(), &req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"payment": req, "warnings": warnings})
+}
+
+type payPaymentRequest struct {
+	Method string `json:"method"`
+}
+
+// payPayment godoc
+// @Summary Mark pending payment as paid
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path int true "payment id"
+// @Param payment body payPaymentRequest false "optional method change"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /payments/{id}/pay [post]
+func (h *Handler) payPayment(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var req payPaymentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	payment, warnings, err := h.Repo.PayPayment(c.Request.Context(), id, req.Method)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"payment": payment, "warnings": warnings})
 }
 
 // deletePayment godoc
-// @Summary Delete payment by order id
+// @Summary Delete pending payment
+// @Description Paid payments cannot be deleted; refund them instead.
 // @Tags payments
-// @Param orderId path int true "order id"
+// @Param id path int true "payment id"
 // @Success 204
-// @Router /payments/{orderId} [delete]
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /payments/{id} [delete]
 func (h *Handler) deletePayment(c *gin.Context) {
-	orderID, ok := parseID(c, "orderId")
+	id, ok := parseID(c, "id")
 	if !ok {
 		return
 	}
-	if err := h.Repo.DeletePayment(c.Request.Context(), orderID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.Repo.DeletePayment(c.Request.Context(), id); err != nil {
+		writeError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
+
+// listRefunds godoc
+// @Summary List refunds of payment
+// @Tags payments
+// @Produce json
+// @Param id path int true "payment id"
+// @Success 200 {array} domain.Refund
+// @Security BearerAuth
+// @Router /payments/{id}/refunds [get]
+func (h *Handler) listRefunds(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	refunds, err := h.Repo.ListRefunds(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, refunds)
+}
+
+type refundRequest struct {
+	Amount float64 `json:"amount"`
+	Reason string  `json:"reason"`
+}
+
+// refundPayment godoc
+// @Summary Refund payment partially or in full
+// @Description Omit amount to refund whatever is left on the payment. The caller is recorded as approved_by.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path int true "payment id"
+// @Param refund body refundRequest true "refund"
+// @Success 201 {object} domain.Refund
+// @Failure 403 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /payments/{id}/refunds [post]
+func (h *Handler) refundPayment(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var req refundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Reason == "" || req.Amount < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required, amount must not be negative"})
+		return
+	}
+	refund := domain.Refund{PaymentID: id, Amount: req.Amount, Reason: req.Reason}
+	if err := h.Repo.RefundPayment(c.Request.Context(), &refund); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, refund)
+}
+
+// getOrderBalance godoc
+// @Summary Order total, payments and outstanding amount
+// @Tags payments
+// @Produce json
+// @Param id path int true "order id"
+// @Success 200 {object} domain.OrderBalance
+// @Security BearerAuth
+// @Router /orders/{id}/payments [get]
+func (h *Handler) getOrderBalance(c *gin.Context) {
+	orderID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	balance, err := h.Repo.GetOrderBalance(c.Request.Context(), orderID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, balance)
+}
+
+// splitOrderBill godoc
+// @Summary Split the outstanding bill into pending payments
+// @Description mode=even splits into parts, mode=items charges each group for its order items, mode=custom uses explicit amounts.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path int true "order id"
+// @Param split body domain.BillSplit true "split"
+// @Success 201 {array} domain.Payment
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /orders/{id}/split [post]
+func (h *Handler) splitOrderBill(c *gin.Context) {
+	orderID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var req domain.BillSplit
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	payments, err := h.Repo.SplitOrderBill(c.Request.Context(), orderID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, payments)
+}
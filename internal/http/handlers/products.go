@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -11,7 +10,7 @@ import (
 
 // RegisterProducts registers product endpoints.
 func RegisterProducts(r *gin.RouterGroup, h *Handler) {
-	g := r.Group("/products")
+	g := r.Group("/products", h.authorize("products"))
 	g.GET("", h.listProducts)
 	g.POST("", h.upsertProduct)
 	g.PUT("/:id", h.upsertProduct)
@@ -20,28 +19,36 @@ func RegisterProducts(r *gin.RouterGroup, h *Handler) {
 
 // listProducts godoc
 // @Summary List products
+// @Description Sortable by id, name. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.
 // @Tags products
 // @Produce json
-// @Param limit query int false "limit"
-// @Success 200 {array} domain.Product
+// @Param unit query string false "unit"
+// @Param is_available query bool false "availability"
+// @Param sort query string false "sort field, prefix - for descending"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "page size, up to 200"
+// @Success 200 {object} domain.Page{items=[]domain.Product}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
 // @Router /products [get]
 func (h *Handler) listProducts(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "200"))
-	products, err := h.Repo.ListProducts(c.Request.Context(), limit)
+	page, err := h.Repo.ListProducts(c.Request.Context(), parseListQuery(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, products)
+	c.JSON(http.StatusOK, page)
 }
 
 // upsertProduct godoc
 // @Summary Create or update product
+// @Description Products are matched by name. is_available only applies to products without stock; once stock is recorded it follows the quantity.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param product body domain.Product true "product"
 // @Success 200 {object} domain.Product
+// @Security BearerAuth
 // @Router /products [post]
 func (h *Handler) upsertProduct(c *gin.Context) {
 	var req domain.Product
@@ -49,12 +56,8 @@ func (h *Handler) upsertProduct(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if req.Name == "" || req.Unit == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "name and unit are required"})
-		return
-	}
 	if err := h.Repo.UpsertProduct(c.Request.Context(), &req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, req)
@@ -62,9 +65,12 @@ func (h *Handler) upsertProduct(c *gin.Context) {
 
 // deleteProduct godoc
 // @Summary Delete product
+// @Description Products with stock movements or recipe lines cannot be deleted.
 // @Tags products
 // @Param id path int true "product id"
 // @Success 204
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
 // @Router /products/{id} [delete]
 func (h *Handler) deleteProduct(c *gin.Context) {
 	id, ok := parseID(c, "id")
@@ -72,7 +78,7 @@ func (h *Handler) deleteProduct(c *gin.Context) {
 		return
 	}
 	if err := h.Repo.DeleteProduct(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -8,7 +8,7 @@ import (
 
 // RegisterReports registers reporting endpoints.
 func RegisterReports(r *gin.RouterGroup, h *Handler) {
-	g := r.Group("/reports")
+	g := r.Group("/reports", h.authorize("reports"))
 	g.GET("/shift-revenue", h.getShiftRevenue)
 	g.GET("/waiters", h.getWaiterPerformance)
 	g.GET("/dishes-availability", h.getDishesAvailability)
@@ -19,11 +19,12 @@ func RegisterReports(r *gin.RouterGroup, h *Handler) {
 // @Tags reports
 // @Produce json
 // @Success 200 {array} domain.ShiftRevenue
+// @Security BearerAuth
 // @Router /reports/shift-revenue [get]
 func (h *Handler) getShiftRevenue(c *gin.Context) {
 	data, err := h.Repo.GetShiftRevenue(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, data)
@@ -34,11 +35,12 @@ func (h *Handler) getShiftRevenue(c *gin.Context) {
 // @Tags reports
 // @Produce json
 // @Success 200 {array} domain.WaiterPerformance
+// @Security BearerAuth
 // @Router /reports/waiters [get]
 func (h *Handler) getWaiterPerformance(c *gin.Context) {
 	data, err := h.Repo.GetWaiterPerformance(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, data)
@@ -49,11 +51,12 @@ func (h *Handler) getWaiterPerformance(c *gin.Context) {
 // @Tags reports
 // @Produce json
 // @Success 200 {array} domain.DishAvailability
+// @Security BearerAuth
 // @Router /reports/dishes-availability [get]
 func (h *Handler) getDishesAvailability(c *gin.Context) {
 	data, err := h.Repo.GetDishesAvailability(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, data)
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/rms/internal/repository"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// RequestID returns middleware that tags each request with an id, taken from
+// the X-Request-ID header when the client sends one, echoes it in the response
+// and passes it to the repository so audit rows can be traced to the request.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if id == "" || len(id) > 128 {
+			buf := make([]byte, 16)
+			_, _ = rand.Read(buf)
+			id = hex.EncodeToString(buf)
+		}
+		c.Header(requestIDHeader, id)
+		c.Request = c.Request.WithContext(repository.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
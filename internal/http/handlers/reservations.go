@@ -10,7 +10,7 @@ import (
 
 // RegisterReservations registers reservation endpoints.
 func RegisterReservations(r *gin.RouterGroup, h *Handler) {
-	g := r.Group("/reservations")
+	g := r.Group("/reservations", h.authorize("reservations"))
 	g.GET("", h.listReservations)
 	g.POST("", h.createReservation)
 	g.PUT("/:id/status", h.updateReservationStatus)
@@ -19,19 +19,28 @@ func RegisterReservations(r *gin.RouterGroup, h *Handler) {
 
 // listReservations godoc
 // @Summary List reservations
+// @Description Latest first by default; sortable by id, reserved_from, reserved_to, created_at. Filters: field=value, field[in]=a,b, field[gte]=v, field[lte]=v.
 // @Tags reservations
 // @Produce json
-// @Param status query string false "status filter"
-// @Success 200 {array} domain.Reservation
+// @Param status query string false "status"
+// @Param table_id query int false "table id"
+// @Param customer_id query int false "customer id"
+// @Param reserved_from[gte] query string false "starting from"
+// @Param reserved_from[lte] query string false "starting until"
+// @Param sort query string false "sort field, prefix - for descending"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "page size, up to 200"
+// @Success 200 {object} domain.Page{items=[]domain.Reservation}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
 // @Router /reservations [get]
 func (h *Handler) listReservations(c *gin.Context) {
-	status := c.Query("status")
-	reservations, err := h.Repo.ListReservations(c.Request.Context(), status)
+	page, err := h.Repo.ListReservations(c.Request.Context(), parseListQuery(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, reservations)
+	c.JSON(http.StatusOK, page)
 }
 
 // createReservation godoc
@@ -41,6 +50,9 @@ func (h *Handler) listReservations(c *gin.Context) {
 // @Produce json
 // @Param reservation body domain.Reservation true "reservation"
 // @Success 201 {object} domain.Reservation
+// @Failure 409 {object} map[string]interface{} "overlap with conflicting_reservation"
+// @Failure 422 {object} map[string]interface{}
+// @Security BearerAuth
 // @Router /reservations [post]
 func (h *Handler) createReservation(c *gin.Context) {
 	var req domain.Reservation
@@ -52,8 +64,15 @@ func (h *Handler) createReservation(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "customer_id and table_id are required"})
 		return
 	}
+	if req.Status == "" {
+		req.Status = "new"
+	}
+	if req.Status != "new" && req.Status != "confirmed" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reservations are created as new or confirmed"})
+		return
+	}
 	if err := h.Repo.CreateReservation(c.Request.Context(), &req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, req)
@@ -61,10 +80,15 @@ func (h *Handler) createReservation(c *gin.Context) {
 
 // updateReservationStatus godoc
 // @Summary Update reservation status
+// @Description Allowed transitions: new -> confirmed/cancelled, confirmed -> completed/cancelled/no_show.
 // @Tags reservations
 // @Param id path int true "reservation id"
 // @Param status query string true "new status"
 // @Success 200
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
 // @Router /reservations/{id}/status [put]
 func (h *Handler) updateReservationStatus(c *gin.Context) {
 	id, ok := parseID(c, "id")
@@ -77,7 +101,7 @@ func (h *Handler) updateReservationStatus(c *gin.Context) {
 		return
 	}
 	if err := h.Repo.UpdateReservationStatus(c.Request.Context(), id, status); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.Status(http.StatusOK)
@@ -88,6 +112,7 @@ func (h *Handler) updateReservationStatus(c *gin.Context) {
 // @Tags reservations
 // @Param id path int true "reservation id"
 // @Success 204
+// @Security BearerAuth
 // @Router /reservations/{id} [delete]
 func (h *Handler) deleteReservation(c *gin.Context) {
 	id, ok := parseID(c, "id")
@@ -95,7 +120,7 @@ func (h *Handler) deleteReservation(c *gin.Context) {
 		return
 	}
 	if err := h.Repo.DeleteReservation(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
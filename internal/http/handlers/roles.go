@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/rms/internal/domain"
+	"github.com/example/rms/internal/repository"
+)
+
+// RegisterRoles registers role and permission endpoints.
+func RegisterRoles(r *gin.RouterGroup, h *Handler) {
+	g := r.Group("/roles", h.authorize("roles"))
+	g.GET("", h.listRoles)
+	g.GET("/permissions", h.listPermissions)
+	g.GET("/:id", h.getRole)
+	g.POST("", h.createRole)
+	g.PUT("/:id", h.updateRole)
+	g.DELETE("/:id", h.deleteRole)
+}
+
+// listRoles godoc
+// @Summary List roles with their permissions
+// @Tags roles
+// @Produce json
+// @Success 200 {array} domain.Role
+// @Security BearerAuth
+// @Router /roles [get]
+func (h *Handler) listRoles(c *gin.Context) {
+	roles, err := h.Repo.ListRoles(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// listPermissions godoc
+// @Summary List permissions that can be granted
+// @Description Besides these, a role may be granted "*" or "<resource>:*".
+// @Tags roles
+// @Produce json
+// @Success 200 {array} string
+// @Security BearerAuth
+// @Router /roles/permissions [get]
+func (h *Handler) listPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, repository.Permissions)
+}
+
+// getRole godoc
+// @Summary Get role
+// @Tags roles
+// @Produce json
+// @Param id path int true "role id"
+// @Success 200 {object} domain.Role
+// @Security BearerAuth
+// @Router /roles/{id} [get]
+func (h *Handler) getRole(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	role, err := h.Repo.GetRole(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, role)
+}
+
+// createRole godoc
+// @Summary Create role
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param role body domain.Role true "role"
+// @Success 201 {object} domain.Role
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /roles [post]
+func (h *Handler) createRole(c *gin.Context) {
+	var req domain.Role
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	if err := h.Repo.CreateRole(c.Request.Context(), &req); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, req)
+}
+
+// updateRole godoc
+// @Summary Update role
+// @Description Replaces the name, description and permissions. System roles cannot be changed.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param id path int true "role id"
+// @Param role body domain.Role true "role"
+// @Success 200 {object} domain.Role
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /roles/{id} [put]
+func (h *Handler) updateRole(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var req domain.Role
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	if err := h.Repo.UpdateRole(c.Request.Context(), id, &req); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+// deleteRole godoc
+// @Summary Delete role
+// @Description System roles and roles still assigned to employees cannot be deleted.
+// @Tags roles
+// @Param id path int true "role id"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Security BearerAuth
+// @Router /roles/{id} [delete]
+func (h *Handler) deleteRole(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.Repo.DeleteRole(c.Request.Context(), id); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
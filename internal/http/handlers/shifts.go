@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/rms/internal/domain"
+	"github.com/example/rms/internal/repository"
+)
+
+// RegisterShifts registers shift lifecycle endpoints.
+func RegisterShifts(r *gin.RouterGroup, h *Handler) {
+	g := r.Group("/shifts", h.authorize("shifts"))
+	g.GET("", h.listShifts)
+	g.GET("/current", h.getCurrentShift)
+	g.POST("/open", h.openShift)
+	g.POST("/:id/close", h.closeShift)
+}
+
+// listShifts godoc
+// @Summary List shifts
+// @Tags shifts
+// @Produce json
+// @Param limit query int false "limit"
+// @Success 200 {array} domain.Shift
+// @Security BearerAuth
+// @Router /shifts [get]
+func (h *Handler) listShifts(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	shifts, err := h.Repo.ListShifts(c.Request.Context(), limit)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shifts)
+}
+
+// getCurrentShift godoc
+// @Summary Get currently open shift
+// @Tags shifts
+// @Produce json
+// @Success 200 {object} domain.Shift
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /shifts/current [get]
+func (h *Handler) getCurrentShift(c *gin.Context) {
+	shift, err := h.Repo.GetCurrentShift(c.Request.Context())
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no open shift"})
+		return
+	}
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shift)
+}
+
+type openShiftRequest struct {
+	Note string `json:"note"`
+}
+
+// openShift godoc
+// @Summary Open a new shift
+// @Description The caller is recorded as opened_by.
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param shift body openShiftRequest true "shift"
+// @Success 201 {object} domain.Shift
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /shifts/open [post]
+func (h *Handler) openShift(c *gin.Context) {
+	var req openShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	openedBy, _ := repository.EmployeeFromContext(c.Request.Context())
+	shift := domain.Shift{OpenedBy: openedBy, Note: req.Note}
+	if err := h.Repo.OpenShift(c.Request.Context(), &shift); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, shift)
+}
+
+type closeShiftRequest struct {
+	ActualRevenue *float64 `json:"actual_revenue"`
+	Note          string   `json:"note"`
+}
+
+// closeShift godoc
+// @Summary Close shift with counted revenue
+// @Description The caller is recorded as closed_by.
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param id path int true "shift id"
+// @Param shift body closeShiftRequest true "closing data"
+// @Success 200 {object} domain.Shift
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /shifts/{id}/close [post]
+func (h *Handler) closeShift(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var req closeShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ActualRevenue == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "actual_revenue is required"})
+		return
+	}
+	closedBy, _ := repository.EmployeeFromContext(c.Request.Context())
+	shift, err := h.Repo.CloseShift(c.Request.Context(), id, closedBy, *req.ActualRevenue, req.Note)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shift)
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/rms/internal/domain"
+	"github.com/example/rms/internal/repository"
+)
+
+// RegisterStock registers warehouse stock endpoints.
+func RegisterStock(r *gin.RouterGroup, h *Handler) {
+	g := r.Group("/stock", h.authorize("stock"))
+	g.GET("", h.listStock)
+	g.GET("/discrepancies", h.getStockDiscrepancies)
+	g.GET("/:productId/movements", h.listStockMovements)
+	g.POST("/receipts", h.receiveStock)
+	g.POST("/write-offs", h.writeOffStock)
+	g.POST("/adjustments", h.adjustStock)
+}
+
+// listStock godoc
+// @Summary List current stock for all products
+// @Tags stock
+// @Produce json
+// @Success 200 {array} domain.ProductStock
+// @Security BearerAuth
+// @Router /stock [get]
+func (h *Handler) listStock(c *gin.Context) {
+	stock, err := h.Repo.ListStock(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stock)
+}
+
+// getStockDiscrepancies godoc
+// @Summary Products whose stock does not match the movement ledger
+// @Tags stock
+// @Produce json
+// @Success 200 {array} domain.StockDiscrepancy
+// @Security BearerAuth
+// @Router /stock/discrepancies [get]
+func (h *Handler) getStockDiscrepancies(c *gin.Context) {
+	data, err := h.Repo.GetStockDiscrepancies(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+// listStockMovements godoc
+// @Summary Stock movement history for product
+// @Tags stock
+// @Produce json
+// @Param productId path int true "product id"
+// @Param limit query int false "limit"
+// @Success 200 {array} domain.StockMovement
+// @Security BearerAuth
+// @Router /stock/{productId}/movements [get]
+func (h *Handler) listStockMovements(c *gin.Context) {
+	productID, ok := parseID(c, "productId")
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "200"))
+	movements, err := h.Repo.ListStockMovements(c.Request.Context(), productID, limit)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, movements)
+}
+
+type stockMovementRequest struct {
+	ProductID int64   `json:"product_id"`
+	Quantity  float64 `json:"quantity"`
+	Reason    string  `json:"reason"`
+}
+
+// receiveStock godoc
+// @Summary Record goods receipt
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Param receipt body stockMovementRequest true "received quantity"
+// @Success 201 {object} domain.StockMovement
+// @Security BearerAuth
+// @Router /stock/receipts [post]
+func (h *Handler) receiveStock(c *gin.Context) {
+	var req stockMovementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ProductID == 0 || req.Quantity <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "product_id and positive quantity are required"})
+		return
+	}
+	h.recordStockMovement(c, domain.StockMovement{
+		ProductID:     req.ProductID,
+		MovementType:  "receipt",
+		QuantityDelta: req.Quantity,
+		Reason:        req.Reason,
+	})
+}
+
+// writeOffStock godoc
+// @Summary Record stock write-off
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Param writeOff body stockMovementRequest true "written-off quantity and reason"
+// @Success 201 {object} domain.StockMovement
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /stock/write-offs [post]
+func (h *Handler) writeOffStock(c *gin.Context) {
+	var req stockMovementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ProductID == 0 || req.Quantity <= 0 || req.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "product_id, positive quantity and reason are required"})
+		return
+	}
+	h.recordStockMovement(c, domain.StockMovement{
+		ProductID:     req.ProductID,
+		MovementType:  "write_off",
+		QuantityDelta: -req.Quantity,
+		Reason:        req.Reason,
+	})
+}
+
+type stockAdjustmentRequest struct {
+	ProductID       int64    `json:"product_id"`
+	CountedQuantity *float64 `json:"counted_quantity"`
+	Reason          string   `json:"reason"`
+}
+
+// adjustStock godoc
+// @Summary Record inventory count adjustment
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Param adjustment body stockAdjustmentRequest true "counted quantity"
+// @Success 201 {object} domain.StockMovement
+// @Security BearerAuth
+// @Router /stock/adjustments [post]
+func (h *Handler) adjustStock(c *gin.Context) {
+	var req stockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ProductID == 0 || req.CountedQuantity == nil || *req.CountedQuantity < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "product_id and non-negative counted_quantity are required"})
+		return
+	}
+	h.recordStockMovement(c, domain.StockMovement{
+		ProductID:     req.ProductID,
+		MovementType:  "adjustment",
+		QuantityAfter: *req.CountedQuantity,
+		Reason:        req.Reason,
+	})
+}
+
+// recordStockMovement stores the movement on behalf of the calling employee.
+func (h *Handler) recordStockMovement(c *gin.Context, m domain.StockMovement) {
+	if employeeID, ok := repository.EmployeeFromContext(c.Request.Context()); ok {
+		m.EmployeeID = &employeeID
+	}
+	if err := h.Repo.RecordStockMovement(c.Request.Context(), &m); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, m)
+}
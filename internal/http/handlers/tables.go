@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -10,8 +12,9 @@ import (
 
 // RegisterTables registers restaurant tables endpoints.
 func RegisterTables(r *gin.RouterGroup, h *Handler) {
-	g := r.Group("/tables")
+	g := r.Group("/tables", h.authorize("tables"))
 	g.GET("", h.listTables)
+	g.GET("/availability", h.listAvailableTables)
 	g.POST("", h.upsertTable)
 	g.PUT("/:id", h.upsertTable) // table_number is unique, so PUT will act similar
 	g.DELETE("/:id", h.deleteTable)
@@ -22,11 +25,47 @@ func RegisterTables(r *gin.RouterGroup, h *Handler) {
 // @Tags tables
 // @Produce json
 // @Success 200 {array} domain.RestaurantTable
+// @Security BearerAuth
 // @Router /tables [get]
 func (h *Handler) listTables(c *gin.Context) {
 	tables, err := h.Repo.ListTables(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tables)
+}
+
+// listAvailableTables godoc
+// @Summary Free tables for a time window, best seat fit first
+// @Tags tables
+// @Produce json
+// @Param from query string true "window start (RFC3339)"
+// @Param to query string true "window end (RFC3339)"
+// @Param guests query int false "number of guests"
+// @Success 200 {array} domain.RestaurantTable
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /tables/availability [get]
+func (h *Handler) listAvailableTables(c *gin.Context) {
+	from, errFrom := time.Parse(time.RFC3339, c.Query("from"))
+	to, errTo := time.Parse(time.RFC3339, c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be RFC3339 timestamps"})
+		return
+	}
+	if !to.After(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be after from"})
+		return
+	}
+	guests, err := strconv.Atoi(c.DefaultQuery("guests", "1"))
+	if err != nil || guests <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "guests must be a positive number"})
+		return
+	}
+	tables, err := h.Repo.ListAvailableTables(c.Request.Context(), from, to, guests)
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, tables)
@@ -39,6 +78,7 @@ func (h *Handler) listTables(c *gin.Context) {
 // @Produce json
 // @Param table body domain.RestaurantTable true "table"
 // @Success 200 {object} domain.RestaurantTable
+// @Security BearerAuth
 // @Router /tables [post]
 func (h *Handler) upsertTable(c *gin.Context) {
 	var req domain.RestaurantTable
@@ -51,7 +91,7 @@ func (h *Handler) upsertTable(c *gin.Context) {
 		return
 	}
 	if err := h.Repo.UpsertTable(c.Request.Context(), &req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, req)
@@ -62,6 +102,7 @@ func (h *Handler) upsertTable(c *gin.Context) {
 // @Tags tables
 // @Param id path int true "table id"
 // @Success 204
+// @Security BearerAuth
 // @Router /tables/{id} [delete]
 func (h *Handler) deleteTable(c *gin.Context) {
 	id, ok := parseID(c, "id")
@@ -69,7 +110,7 @@ func (h *Handler) deleteTable(c *gin.Context) {
 		return
 	}
 	if err := h.Repo.DeleteTable(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -13,7 +13,7 @@ import (
 // NewRouter wires all routes and handlers.
 func NewRouter(repo *repository.Repository) *gin.Engine {
 	r := gin.New()
-	r.Use(gin.Logger(), gin.Recovery())
+	r.Use(gin.Logger(), gin.Recovery(), handlers.RequestID())
 
 	h := &handlers.Handler{Repo: repo}
 
@@ -26,17 +26,23 @@ func NewRouter(repo *repository.Repository) *gin.Engine {
 
 	api := r.Group("/api")
 	{
+		handlers.RegisterAuth(api, h)
 		handlers.RegisterCustomers(api, h)
 		handlers.RegisterEmployees(api, h)
+		handlers.RegisterRoles(api, h)
 		handlers.RegisterTables(api, h)
 		handlers.RegisterMenuCategories(api, h)
 		handlers.RegisterDishes(api, h)
 		handlers.RegisterProducts(api, h)
+		handlers.RegisterStock(api, h)
 		handlers.RegisterReservations(api, h)
 		handlers.RegisterOrders(api, h)
 		handlers.RegisterPayments(api, h)
+		handlers.RegisterShifts(api, h)
 		handlers.RegisterReports(api, h)
 		handlers.RegisterBatchImport(api, h)
+		handlers.RegisterImportErrors(api, h)
+		handlers.RegisterAudit(api, h)
 	}
 
 	return r
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/example/rms/internal/repository"
+)
+
+// noShowRequestID tags the job's changes in audit_log.
+const noShowRequestID = "job:no_show"
+
+// RunNoShowMarker periodically marks overdue confirmed reservations as no_show
+// until ctx is cancelled.
+func RunNoShowMarker(ctx context.Context, repo *repository.Repository, grace, interval time.Duration) {
+	ctx = repository.WithRequestID(ctx, noShowRequestID)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		ids, err := repo.MarkNoShowReservations(ctx, grace)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			log.Printf("no-show job: %v", err)
+		case len(ids) > 0:
+			log.Printf("no-show job: marked reservations %v as no_show", ids)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+)
+
+type (
+	employeeKey  struct{}
+	requestIDKey struct{}
+)
+
+// WithEmployee returns a context carrying the authenticated employee's id.
+func WithEmployee(ctx context.Context, employeeID int64) context.Context {
+	return context.WithValue(ctx, employeeKey{}, employeeID)
+}
+
+// EmployeeFromContext returns the employee id set by WithEmployee.
+func EmployeeFromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(employeeKey{}).(int64)
+	return id, ok
+}
+
+// WithRequestID returns a context carrying the id of the HTTP request.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request id set by WithRequestID.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// beginTx starts a transaction and stores the acting employee and request id
+// in the transaction-local settings rms.actor_id and rms.request_id, which
+// fn_audit copies into audit_log.
+func (r *Repository) beginTx(ctx context.Context) (*sql.Tx, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	var actor string
+	if id, ok := EmployeeFromContext(ctx); ok {
+		actor = strconv.FormatInt(id, 10)
+	}
+	requestID := RequestIDFromContext(ctx)
+	if actor == "" && requestID == "" {
+		return tx, nil
+	}
+	_, err = tx.ExecContext(ctx, `SELECT set_config('rms.actor_id', $1, true), set_config('rms.request_id', $2, true)`, actor, requestID)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// exec runs a single write statement in its own transaction so that it is
+// audited with the acting employee.
+func (r *Repository) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	var res sql.Result
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		res, err = tx.ExecContext(ctx, query, args...)
+		return err
+	})
+	return res, err
+}
+
+// execReturning is exec for statements with a RETURNING clause scanned into dest.
+func (r *Repository) execReturning(ctx context.Context, query string, args []interface{}, dest ...interface{}) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx, query, args...).Scan(dest...)
+	})
+}
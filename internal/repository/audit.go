@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/example/rms/internal/domain"
+)
+
+// auditedTables are the tables with the trg_audit_* trigger.
+var auditedTables = map[string]bool{
+	"customers": true, "employees": true, "products": true, "dishes": true, "dish_ingredients": true, "reservations": true,
+	"shifts": true, "orders": true, "order_items": true, "payments": true, "refunds": true,
+}
+
+const auditColumns = `id, table_name, record_id, operation, changed_at, COALESCE(changed_by,''), request_id, old_data, new_data`
+
+var auditList = listSpec{
+	table:   "audit_log",
+	columns: auditColumns,
+	fields: map[string]listField{
+		"id":         {column: "id", kind: "bigint", sortable: true},
+		"table":      {column: "table_name", kind: "text"},
+		"record_id":  {column: "record_id", kind: "bigint"},
+		"operation":  {column: "operation", kind: "text"},
+		"actor":      {column: "changed_by", kind: "text"},
+		"request_id": {column: "request_id", kind: "text"},
+		"changed_at": {column: "changed_at", kind: "timestamp", sortable: true},
+	},
+	defaultSort: "-id",
+}
+
+// ListAudit pages through audit_log, newest first unless sorted otherwise.
+func (r *Repository) ListAudit(ctx context.Context, q ListQuery) (domain.Page, error) {
+	return listPage(ctx, r.DB, auditList, q, scanAuditEntry)
+}
+
+func scanAuditEntry(row rowScanner) (domain.AuditEntry, error) {
+	var e domain.AuditEntry
+	var requestID sql.NullString
+	var oldData, newData []byte
+	if err := row.Scan(&e.ID, &e.Table, &e.RecordID, &e.Operation, &e.ChangedAt, &e.ChangedBy, &requestID, &oldData, &newData); err != nil {
+		return e, err
+	}
+	e.RequestID = scanNullableString(requestID)
+	e.OldData, e.NewData = oldData, newData
+	return e, nil
+}
+
+// GetRecordHistory returns every audited change of one record, oldest first.
+func (r *Repository) GetRecordHistory(ctx context.Context, table string, recordID int64) ([]domain.RecordVersion, error) {
+	if !auditedTables[table] {
+		return nil, fmt.Errorf("%w: %q", ErrNotAudited, table)
+	}
+	return r.history(ctx, `table_name=$1 AND record_id=$2`, table, recordID)
+}
+
+// GetOrderHistory returns the changes of the order together with those of its
+// items and payments, so removed items and edited payments show up in one timeline.
+func (r *Repository) GetOrderHistory(ctx context.Context, orderID int64) ([]domain.RecordVersion, error) {
+	return r.history(ctx, `
+		(table_name='orders' AND record_id=$1)
+		OR (table_name IN ('order_items','payments') AND COALESCE(new_data, old_data)->>'order_id' = $2)`,
+		orderID, strconv.FormatInt(orderID, 10))
+}
+
+// GetPaymentHistory returns the changes of the payment and its refunds.
+func (r *Repository) GetPaymentHistory(ctx context.Context, paymentID int64) ([]domain.RecordVersion, error) {
+	return r.history(ctx, `
+		(table_name='payments' AND record_id=$1)
+		OR (table_name='refunds' AND COALESCE(new_data, old_data)->>'payment_id' = $2)`,
+		paymentID, strconv.FormatInt(paymentID, 10))
+}
+
+func (r *Repository) history(ctx context.Context, cond string, args ...interface{}) ([]domain.RecordVersion, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+auditColumns+` FROM audit_log WHERE `+cond+` ORDER BY changed_at, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []domain.RecordVersion{}
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes, err := diffVersions(e.OldData, e.NewData)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, domain.RecordVersion{
+			AuditID:   e.ID,
+			Table:     e.Table,
+			RecordID:  e.RecordID,
+			Operation: e.Operation,
+			ChangedAt: e.ChangedAt,
+			ChangedBy: e.ChangedBy,
+			RequestID: e.RequestID,
+			Changes:   changes,
+		})
+	}
+	return res, rows.Err()
+}
+
+// diffVersions lists the top-level fields whose values differ between two row
+// snapshots, sorted by field name. A missing snapshot counts as all nulls.
+func diffVersions(oldData, newData []byte) ([]domain.FieldChange, error) {
+	var before, after map[string]json.RawMessage
+	if len(oldData) > 0 {
+		if err := json.Unmarshal(oldData, &before); err != nil {
+			return nil, err
+		}
+	}
+	if len(newData) > 0 {
+		if err := json.Unmarshal(newData, &after); err != nil {
+			return nil, err
+		}
+	}
+	fields := make(map[string]bool, len(before)+len(after))
+	for f := range before {
+		fields[f] = true
+	}
+	for f := range after {
+		fields[f] = true
+	}
+	changes := []domain.FieldChange{}
+	for f := range fields {
+		o, n := before[f], after[f]
+		if bytes.Equal(o, n) {
+			continue
+		}
+		changes = append(changes, domain.FieldChange{Field: f, Old: o, New: n})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
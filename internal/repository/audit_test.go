@@ -0,0 +1,81 @@
+package repository
+
+import "testing"
+
+func TestDiffVersions(t *testing.T) {
+	type change struct{ field, old, new string }
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		want    []change
+		wantErr bool
+	}{
+		{
+			name: "insert lists every field",
+			new:  `{"id":1,"name":"Анна"}`,
+			want: []change{{"id", "", "1"}, {"name", "", `"Анна"`}},
+		},
+		{
+			name: "delete lists every field",
+			old:  `{"id":1,"name":"Анна"}`,
+			want: []change{{"id", "1", ""}, {"name", `"Анна"`, ""}},
+		},
+		{
+			name: "update lists changed fields sorted",
+			old:  `{"id":1,"phone":"79120000000","name":"Анна","email":null}`,
+			new:  `{"id":1,"phone":"79123456789","name":"Анна","email":"anna@example.com"}`,
+			want: []change{{"email", "null", `"anna@example.com"`}, {"phone", `"79120000000"`, `"79123456789"`}},
+		},
+		{
+			name: "nested values compare as a whole",
+			old:  `{"id":1,"meta":{"a":1}}`,
+			new:  `{"id":1,"meta":{"a":2}}`,
+			want: []change{{"meta", `{"a":1}`, `{"a":2}`}},
+		},
+		{
+			name: "identical versions",
+			old:  `{"id":1,"name":"Анна"}`,
+			new:  `{"id":1,"name":"Анна"}`,
+			want: []change{},
+		},
+		{
+			name: "added column",
+			old:  `{"id":1}`,
+			new:  `{"id":1,"note":"vip"}`,
+			want: []change{{"note", "", `"vip"`}},
+		},
+		{
+			name:    "malformed snapshot",
+			old:     `{"id":`,
+			new:     `{"id":1}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diffVersions([]byte(tt.old), []byte(tt.new))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == nil {
+				t.Fatal("changes must be an empty list, not nil")
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d changes %v, want %d", len(got), got, len(tt.want))
+			}
+			for i, w := range tt.want {
+				g := got[i]
+				if g.Field != w.field || string(g.Old) != w.old || string(g.New) != w.new {
+					t.Errorf("change %d = {%s %s %s}, want {%s %s %s}", i, g.Field, g.Old, g.New, w.field, w.old, w.new)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/example/rms/internal/domain"
+)
+
+var pinFormat = regexp.MustCompile(`^[0-9]{4,8}$`)
+
+const minPasswordLength = 8
+
+// maxLockout caps the doubling lock after repeated failed logins.
+const maxLockout = 24 * time.Hour
+
+// dummyHash is compared against when no hash exists so that unknown logins
+// take as long as wrong secrets.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy secret"), bcrypt.DefaultCost)
+
+// SetEmployeeCredentials stores a bcrypt hash of the PIN and/or password; an
+// empty value keeps the current one. Hashes live in employee_credentials, which
+// is not audited, so they never reach audit_log.
+func (r *Repository) SetEmployeeCredentials(ctx context.Context, employeeID int64, pin, password string) error {
+	pinHash, passwordHash, err := hashCredentials(pin, password)
+	if err != nil {
+		return err
+	}
+	_, err = r.exec(ctx, upsertCredentials, pinHash, passwordHash, employeeID)
+	return err
+}
+
+const upsertCredentials = `
+	INSERT INTO employee_credentials(employee_id, pin_hash, password_hash)
+	VALUES ($3, $1, $2)
+	ON CONFLICT (employee_id) DO UPDATE
+	SET pin_hash=COALESCE(EXCLUDED.pin_hash, employee_credentials.pin_hash),
+	    password_hash=COALESCE(EXCLUDED.password_hash, employee_credentials.password_hash),
+	    updated_at=now()`
+
+// hashCredentials validates and hashes the PIN and password; an empty value
+// gives a NULL hash.
+func hashCredentials(pin, password string) (pinHash, passwordHash sql.NullString, err error) {
+	if pin != "" {
+		if !pinFormat.MatchString(pin) {
+			return pinHash, passwordHash, fmt.Errorf("%w: pin must be 4 to 8 digits", ErrWeakCredentials)
+		}
+		h, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+		if err != nil {
+			return pinHash, passwordHash, err
+		}
+		pinHash = sql.NullString{String: string(h), Valid: true}
+	}
+	if password != "" {
+		if len(password) < minPasswordLength {
+			return pinHash, passwordHash, fmt.Errorf("%w: password must be at least %d characters", ErrWeakCredentials, minPasswordLength)
+		}
+		h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return pinHash, passwordHash, err
+		}
+		passwordHash = sql.NullString{String: string(h), Valid: true}
+	}
+	return pinHash, passwordHash, nil
+}
+
+// BootstrapAdmin gives the employee with the phone the system admin role and
+// the password, creating the employee if the phone is unknown. It does nothing
+// once an active admin has a password, so the first login of a fresh install
+// does not depend on test data. It reports whether anything was changed.
+func (r *Repository) BootstrapAdmin(ctx context.Context, fullName, phone, password string) (bool, error) {
+	if password == "" {
+		return false, fmt.Errorf("%w: the first admin needs a password", ErrWeakCredentials)
+	}
+	_, passwordHash, err := hashCredentials("", password)
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		// Serializes concurrent starts so only one of them creates the admin.
+		if _, err := tx.ExecContext(ctx, `LOCK TABLE employee_credentials IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+			return err
+		}
+		var exists bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM employees e
+				JOIN roles ro ON ro.id = e.role_id
+				JOIN employee_credentials ec ON ec.employee_id = e.id
+				WHERE ro.name='admin' AND ro.is_system AND e.is_active AND ec.password_hash IS NOT NULL)`).Scan(&exists)
+		if err != nil || exists {
+			return err
+		}
+		var employeeID int64
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO employees(full_name, phone, role_id)
+			SELECT $1, $2, id FROM roles WHERE name='admin' AND is_system
+			ON CONFLICT (phone) DO UPDATE SET role_id=EXCLUDED.role_id, is_active=TRUE
+			RETURNING id`, fullName, phone).Scan(&employeeID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("the system admin role is missing; apply migrations/schema.sql")
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, upsertCredentials, sql.NullString{}, passwordHash, employeeID); err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+	return changed, err
+}
+
+// LoginWithPIN authenticates a POS terminal login by employee id and PIN.
+func (r *Repository) LoginWithPIN(ctx context.Context, employeeID int64, pin string) (domain.Employee, error) {
+	return r.login(ctx, `e.id=$1`, employeeID, "pin_hash", pin)
+}
+
+// LoginWithPassword authenticates a back office login by email or phone and password.
+func (r *Repository) LoginWithPassword(ctx context.Context, login, password string) (domain.Employee, error) {
+	return r.login(ctx, `(e.email=$1 OR e.phone=$1)`, login, "password_hash", password)
+}
+
+// CheckCredentials confirms that pin, or password when pin is empty, is the
+// employee's current secret.
+func (r *Repository) CheckCredentials(ctx context.Context, employeeID int64, pin, password string) error {
+	var err error
+	if pin != "" {
+		_, err = r.LoginWithPIN(ctx, employeeID, pin)
+	} else {
+		_, err = r.login(ctx, `e.id=$1`, employeeID, "password_hash", password)
+	}
+	return err
+}
+
+// login loads the active employee matching cond and compares secret with the
+// stored hash. Unknown, inactive and mismatching logins are reported alike.
+// Every mismatch counts towards a lock of the employee's login; the
+// credentials row is locked so parallel guesses are counted one by one.
+func (r *Repository) login(ctx context.Context, cond string, key interface{}, hashColumn, secret string) (domain.Employee, error) {
+	var e domain.Employee
+	var loginErr error
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		var email, hash sql.NullString
+		var failed int
+		var lockedUntil sql.NullTime
+		var locked bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT e.id, e.full_name, e.phone, e.email, e.role_id, e.hired_at, e.is_active, ec.`+hashColumn+`,
+				ec.failed_logins, ec.locked_until, COALESCE(ec.locked_until > now(), false)
+			FROM employees e
+			JOIN employee_credentials ec ON ec.employee_id = e.id
+			WHERE `+cond+` AND e.is_active
+			FOR UPDATE OF ec`, key).
+			Scan(&e.ID, &e.FullName, &e.Phone, &email, &e.RoleID, &e.HiredAt, &e.IsActive, &hash, &failed, &lockedUntil, &locked)
+		if errors.Is(err, sql.ErrNoRows) {
+			_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(secret))
+			loginErr = ErrInvalidCredentials
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if locked {
+			loginErr = fmt.Errorf("%w: try again after %s", ErrLoginLocked, lockedUntil.Time.Format(time.RFC3339))
+			return nil
+		}
+		stored := dummyHash
+		if hash.Valid {
+			stored = []byte(hash.String)
+		}
+		if bcrypt.CompareHashAndPassword(stored, []byte(secret)) != nil || !hash.Valid {
+			loginErr = ErrInvalidCredentials
+			return r.recordFailedLogin(ctx, tx, e.ID, failed+1)
+		}
+		e.Email = scanNullableString(email)
+		if failed == 0 {
+			return nil
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE employee_credentials SET failed_logins=0, locked_until=NULL WHERE employee_id=$1`, e.ID)
+		return err
+	})
+	if err == nil {
+		err = loginErr
+	}
+	if err != nil {
+		return domain.Employee{}, err
+	}
+	return e, nil
+}
+
+// recordFailedLogin stores the failure count and, from AuthMaxFailedLogins on,
+// locks the login for AuthLockout doubled with every further failure.
+func (r *Repository) recordFailedLogin(ctx context.Context, tx *sql.Tx, employeeID int64, failed int) error {
+	var lock sql.NullFloat64
+	if over := failed - r.Cfg.AuthMaxFailedLogins; over >= 0 {
+		d := maxLockout
+		if over < 32 {
+			d = r.Cfg.AuthLockout << over
+		}
+		if d <= 0 || d > maxLockout {
+			d = maxLockout
+		}
+		lock = sql.NullFloat64{Float64: d.Seconds(), Valid: true}
+	}
+	_, err := tx.ExecContext(ctx, `
+		UPDATE employee_credentials
+		SET failed_logins=$1, locked_until=now() + $2 * interval '1 second'
+		WHERE employee_id=$3`, failed, lock, employeeID)
+	return err
+}
+
+// GetActiveEmployee returns the employee unless they are unknown or deactivated.
+func (r *Repository) GetActiveEmployee(ctx context.Context, employeeID int64) (domain.Employee, error) {
+	var e domain.Employee
+	var email sql.NullString
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT id, full_name, phone, email, role_id, hired_at, is_active
+		FROM employees WHERE id=$1 AND is_active`, employeeID).
+		Scan(&e.ID, &e.FullName, &e.Phone, &email, &e.RoleID, &e.HiredAt, &e.IsActive)
+	if errors.Is(err, sql.ErrNoRows) {
+		return e, fmt.Errorf("%w: employee %d is unknown or inactive", ErrUnauthenticated, employeeID)
+	}
+	e.Email = scanNullableString(email)
+	return e, err
+}
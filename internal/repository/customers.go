@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/example/rms/internal/domain"
+)
+
+var customerList = listSpec{
+	table:   "customers",
+	columns: "id, full_name, phone, email, created_at, vip_level",
+	fields: map[string]listField{
+		"id":         {column: "id", kind: "bigint", sortable: true},
+		"full_name":  {column: "full_name", kind: "text", sortable: true},
+		"vip_level":  {column: "vip_level", kind: "int", sortable: true},
+		"created_at": {column: "created_at", kind: "timestamp", sortable: true},
+	},
+	custom: map[string]listFilterFunc{
+		"phone": func(v string, arg func(interface{}) string) (string, error) {
+			// Without digits the prefix would match every customer.
+			if normalizePhone(v) == "" {
+				return "", fmt.Errorf("%w: phone must contain digits", ErrInvalidListQuery)
+			}
+			var conds []string
+			for _, p := range phonePrefixes(v) {
+				conds = append(conds, "phone_normalized LIKE "+arg(p+"%"))
+			}
+			return "(" + strings.Join(conds, " OR ") + ")", nil
+		},
+		"name": func(v string, arg func(interface{}) string) (string, error) {
+			return "full_name ILIKE " + arg("%"+escapeLike(v)+"%"), nil
+		},
+		"email": func(v string, arg func(interface{}) string) (string, error) {
+			return "lower(email) = lower(" + arg(v) + ")", nil
+		},
+	},
+	defaultSort: "-created_at",
+}
+
+// ListCustomers returns a page of customers, newest first by default. Besides
+// the common filters it searches by phone prefix regardless of formatting,
+// by case-insensitive name substring and by email.
+func (r *Repository) ListCustomers(ctx context.Context, q ListQuery) (domain.Page, error) {
+	return listPage(ctx, r.DB, customerList, q, scanCustomer)
+}
+
+func scanCustomer(row rowScanner) (domain.Customer, error) {
+	var c domain.Customer
+	var email sql.NullString
+	if err := row.Scan(&c.ID, &c.FullName, &c.Phone, &email, &c.CreatedAt, &c.VIPLevel); err != nil {
+		return c, err
+	}
+	c.Email = scanNullableString(email)
+	return c, nil
+}
+
+var nonDigits = regexp.MustCompile(`\D`)
+
+// normalizePhone keeps the digits of a phone number and replaces the Russian
+// trunk prefix 8 of an 11-digit number with 7, matching customers.phone_normalized.
+func normalizePhone(phone string) string {
+	digits := nonDigits.ReplaceAllString(phone, "")
+	if len(digits) == 11 && strings.HasPrefix(digits, "8") {
+		digits = "7" + digits[1:]
+	}
+	return digits
+}
+
+// phonePrefixes returns the phone_normalized prefixes a typed fragment of a
+// number can match: the digits as typed, with the country code 7 in front of
+// a number typed without it, and, while a number starting with the trunk
+// prefix 8 is still shorter than 11 digits, with that 8 read as 7.
+func phonePrefixes(v string) []string {
+	phone := normalizePhone(v)
+	if strings.HasPrefix(phone, "7") {
+		return []string{phone}
+	}
+	prefixes := []string{phone, "7" + phone}
+	if strings.HasPrefix(phone, "8") && len(phone) < 11 {
+		prefixes = append(prefixes, "7"+phone[1:])
+	}
+	return prefixes
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// customerProfileLimit caps the reservations and orders returned in a customer profile.
+const customerProfileLimit = 20
+
+// GetCustomerDetails returns the customer profile: loyalty standing with the
+// lifetime spend from get_customer_total_spent, latest reservations, latest
+// orders with their totals and the most ordered dishes.
+func (r *Repository) GetCustomerDetails(ctx context.Context, id int64) (domain.CustomerDetails, error) {
+	var d domain.CustomerDetails
+	var email sql.NullString
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT id, full_name, phone, email, created_at, vip_level, get_customer_total_spent(id)
+		FROM customers WHERE id=$1`, id).
+		Scan(&d.ID, &d.FullName, &d.Phone, &email, &d.CreatedAt, &d.VIPLevel, &d.Loyalty.TotalSpent)
+	if err != nil {
+		return d, err
+	}
+	d.Email = scanNullableString(email)
+
+	d.Loyalty.Level = d.VIPLevel
+	d.Loyalty.DiscountPercent = r.levelDiscount(d.VIPLevel)
+	if d.VIPLevel < len(r.Cfg.LoyaltyThresholds) {
+		next := d.VIPLevel + 1
+		threshold := r.Cfg.LoyaltyThresholds[d.VIPLevel]
+		left := fromCents(max64(toCents(threshold)-toCents(d.Loyalty.TotalSpent), 0))
+		d.Loyalty.NextLevel = &next
+		d.Loyalty.NextLevelSpend = &threshold
+		d.Loyalty.SpendToNextLevel = &left
+	}
+
+	var lastVisit sql.NullTime
+	err = r.DB.QueryRowContext(ctx, `SELECT COUNT(*), MAX(created_at) FROM orders WHERE customer_id=$1 AND status='closed'`, id).
+		Scan(&d.VisitsCount, &lastVisit)
+	if err != nil {
+		return d, err
+	}
+	if lastVisit.Valid {
+		val := lastVisit.Time
+		d.LastVisitAt = &val
+	}
+
+	if d.Reservations, err = r.listCustomerReservations(ctx, id); err != nil {
+		return d, err
+	}
+	if d.Orders, err = r.listCustomerOrders(ctx, id); err != nil {
+		return d, err
+	}
+	if d.FavouriteDishes, err = r.listFavouriteDishes(ctx, id); err != nil {
+		return d, err
+	}
+	return d, nil
+}
+
+func (r *Repository) listCustomerReservations(ctx context.Context, customerID int64) ([]domain.Reservation, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT id, customer_id, table_id, reserved_from, reserved_to, status, created_at
+		FROM reservations WHERE customer_id=$1
+		ORDER BY reserved_from DESC LIMIT $2`, customerID, customerProfileLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []domain.Reservation{}
+	for rows.Next() {
+		var rsv domain.Reservation
+		if err := rows.Scan(&rsv.ID, &rsv.CustomerID, &rsv.TableID, &rsv.ReservedFrom, &rsv.ReservedTo, &rsv.Status, &rsv.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, rsv)
+	}
+	return res, rows.Err()
+}
+
+// listCustomerOrders returns the latest orders priced by the pricing engine.
+func (r *Repository) listCustomerOrders(ctx context.Context, customerID int64) ([]domain.CustomerOrder, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+orderColumns+` FROM orders WHERE customer_id=$1 ORDER BY created_at DESC LIMIT $2`, customerID, customerProfileLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []domain.CustomerOrder{}
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, domain.CustomerOrder{Order: o})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range res {
+		if res[i].Total, err = orderTotal(ctx, r.DB, res[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// listFavouriteDishes ranks the dishes the customer ordered by portions, ignoring cancelled orders.
+func (r *Repository) listFavouriteDishes(ctx context.Context, customerID int64) ([]domain.FavouriteDish, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT d.id, d.name, SUM(oi.quantity) AS portions, COUNT(DISTINCT o.id) AS orders_count
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		JOIN dishes d ON d.id = oi.dish_id
+		WHERE o.customer_id=$1 AND o.status <> 'cancelled'
+		GROUP BY d.id, d.name
+		ORDER BY portions DESC, orders_count DESC, d.name
+		LIMIT 5`, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []domain.FavouriteDish{}
+	for rows.Next() {
+		var f domain.FavouriteDish
+		if err := rows.Scan(&f.DishID, &f.Name, &f.Portions, &f.OrdersCount); err != nil {
+			return nil, err
+		}
+		res = append(res, f)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string
+	}{
+		{"+7 (912) 345-67-89", "79123456789"},
+		{"8 912 345 67 89", "79123456789"},
+		{"89123456789", "79123456789"},
+		{"79123456789", "79123456789"},
+		{"9123456789", "9123456789"},
+		// Only an 11-digit number has a trunk prefix to replace.
+		{"8912", "8912"},
+		{"891234567890", "891234567890"},
+		{"+44 20 7946 0958", "442079460958"},
+		{"", ""},
+		{"n/a", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizePhone(tt.phone); got != tt.want {
+			t.Errorf("normalizePhone(%q) = %q, want %q", tt.phone, got, tt.want)
+		}
+	}
+}
+
+func TestPhonePrefixes(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"+7 912", []string{"7912"}},
+		{"912", []string{"912", "7912"}},
+		{"8 912", []string{"8912", "78912", "7912"}},
+		{"8 912 345 67 89", []string{"79123456789"}},
+	}
+	for _, tt := range tests {
+		if got := phonePrefixes(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("phonePrefixes(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCustomerPhoneFilter(t *testing.T) {
+	tests := []struct {
+		value    string
+		want     string
+		wantArgs []interface{}
+		wantErr  error
+	}{
+		{value: "+7 912", want: "(phone_normalized LIKE $1)", wantArgs: []interface{}{"7912%"}},
+		{value: "912", want: "(phone_normalized LIKE $1 OR phone_normalized LIKE $2)", wantArgs: []interface{}{"912%", "7912%"}},
+		{value: "abc", wantErr: ErrInvalidListQuery},
+		{value: "+", wantErr: ErrInvalidListQuery},
+		{value: " ", wantErr: ErrInvalidListQuery},
+	}
+	for _, tt := range tests {
+		var args []interface{}
+		arg := func(v interface{}) string {
+			args = append(args, v)
+			return "$" + strconv.Itoa(len(args))
+		}
+		got, err := customerList.condition(ListFilter{Field: "phone", Op: "eq", Values: []string{tt.value}}, arg)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("phone=%q: err = %v, want %v", tt.value, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("phone=%q: %v", tt.value, err)
+			continue
+		}
+		if got != tt.want || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("phone=%q: condition = %q %v, want %q %v", tt.value, got, args, tt.want, tt.wantArgs)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/example/rms/internal/domain"
+)
+
+// Dish ingredients (recipes)
+func (r *Repository) ListDishIngredients(ctx context.Context, dishID int64) ([]domain.DishIngredient, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT id, dish_id, product_id, quantity FROM dish_ingredients WHERE dish_id=$1 ORDER BY id`, dishID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.DishIngredient
+	for rows.Next() {
+		var di domain.DishIngredient
+		if err := rows.Scan(&di.ID, &di.DishID, &di.ProductID, &di.Quantity); err != nil {
+			return nil, err
+		}
+		res = append(res, di)
+	}
+	return res, rows.Err()
+}
+
+func (r *Repository) AddDishIngredient(ctx context.Context, di *domain.DishIngredient) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return saveDishIngredient(ctx, tx, di, false)
+	})
+}
+
+// saveDishIngredient inserts or updates a recipe line matched by dish and
+// product, or by id with keepID, which is how a recipe line is restored.
+func saveDishIngredient(ctx context.Context, tx *sql.Tx, di *domain.DishIngredient, keepID bool) error {
+	if di.DishID == 0 || di.ProductID == 0 || di.Quantity <= 0 {
+		return fmt.Errorf("%w: product_id and positive quantity are required", ErrInvalidRecord)
+	}
+	key := "dish_id, product_id"
+	if keepID {
+		key = "id"
+	}
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO dish_ingredients(id, dish_id, product_id, quantity)
+		VALUES (COALESCE($1, nextval(pg_get_serial_sequence('dish_ingredients', 'id'))),$2,$3,$4)
+		ON CONFLICT (`+key+`) DO UPDATE SET dish_id=EXCLUDED.dish_id, product_id=EXCLUDED.product_id, quantity=EXCLUDED.quantity
+		RETURNING id`,
+		sql.NullInt64{Int64: di.ID, Valid: keepID}, di.DishID, di.ProductID, di.Quantity).
+		Scan(&di.ID)
+}
+
+func (r *Repository) UpdateDishIngredientQuantity(ctx context.Context, dishID, productID int64, quantity float64) error {
+	res, err := r.exec(ctx, `UPDATE dish_ingredients SET quantity=$1 WHERE dish_id=$2 AND product_id=$3`, quantity, dishID, productID)
+	if err != nil {
+		return err
+	}
+	aff, _ := res.RowsAffected()
+	if aff == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *Repository) DeleteDishIngredient(ctx context.Context, dishID, productID int64) error {
+	_, err := r.exec(ctx, `DELETE FROM dish_ingredients WHERE dish_id=$1 AND product_id=$2`, dishID, productID)
+	return err
+}
+
+// ReplaceDishIngredients swaps the whole recipe of a dish atomically.
+func (r *Repository) ReplaceDishIngredients(ctx context.Context, dishID int64, items []domain.DishIngredient) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		// Lock the dish so concurrent replacements of the same recipe serialize.
+		var id int64
+		if err := tx.QueryRowContext(ctx, `SELECT id FROM dishes WHERE id=$1 FOR UPDATE`, dishID).Scan(&id); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM dish_ingredients WHERE dish_id=$1`, dishID); err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].DishID = dishID
+			err := tx.QueryRowContext(ctx, `
+				INSERT INTO dish_ingredients(dish_id, product_id, quantity)
+				VALUES ($1,$2,$3)
+				RETURNING id`, dishID, items[i].ProductID, items[i].Quantity).Scan(&items[i].ID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
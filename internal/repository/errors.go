@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/example/rms/internal/domain"
+)
+
+// Domain errors returned by the repository so handlers can map them to HTTP statuses.
+var (
+	ErrShiftAlreadyOpen   = errors.New("another shift is already open")
+	ErrShiftAlreadyClosed = errors.New("shift is already closed")
+	ErrNoOpenShift        = errors.New("no open shift: open a shift before taking orders")
+	ErrInsufficientStock  = errors.New("insufficient stock")
+	ErrDishUnavailable    = errors.New("dish cannot be ordered")
+	ErrOverrideForbidden  = errors.New("price override requires an authorized approver")
+	ErrInvalidStatus      = errors.New("invalid status")
+	ErrIllegalTransition  = errors.New("illegal status transition")
+	ErrReservationOverlap = errors.New("table is already reserved for this time")
+	ErrInvalidSplit       = errors.New("invalid bill split")
+	ErrInvalidRefund      = errors.New("invalid refund")
+	ErrRefundForbidden    = errors.New("refund requires an authorized approver")
+	ErrPaymentExceedsDue  = errors.New("payment exceeds the amount due")
+	ErrOrderFinalized     = errors.New("order is already closed or cancelled")
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrInvalidListQuery   = errors.New("invalid list query")
+	ErrUnauthenticated    = errors.New("unknown or inactive employee")
+	ErrPermissionDenied   = errors.New("permission denied")
+	ErrSystemRole         = errors.New("system roles cannot be changed")
+	ErrInvalidPermission  = errors.New("invalid permission")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrWeakCredentials    = errors.New("credentials do not meet requirements")
+	ErrLoginLocked        = errors.New("too many failed logins")
+	ErrNotAudited         = errors.New("table is not audited")
+	ErrInvalidRecord      = errors.New("invalid record")
+	ErrInvalidRestore     = errors.New("cannot restore record")
+	ErrRestoreConflict    = errors.New("record cannot be restored over its current state")
+	ErrImportFailed       = errors.New("import failed")
+)
+
+// ReservationConflictError carries the reservation that blocks a new one so
+// clients can offer an alternative slot.
+type ReservationConflictError struct {
+	Conflicting domain.Reservation
+}
+
+func (e *ReservationConflictError) Error() string {
+	return fmt.Sprintf("%v: reservation #%d holds table %d from %s to %s", ErrReservationOverlap,
+		e.Conflicting.ID, e.Conflicting.TableID,
+		e.Conflicting.ReservedFrom.Format("2006-01-02 15:04"), e.Conflicting.ReservedTo.Format("2006-01-02 15:04"))
+}
+
+func (e *ReservationConflictError) Unwrap() error {
+	return ErrReservationOverlap
+}
+
+// isUniqueViolation reports whether err is a unique violation on the given constraint or index.
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
+
+// isExclusionViolation reports whether err is an exclusion violation on the given constraint.
+func isExclusionViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "23P01" && pqErr.Constraint == constraint
+}
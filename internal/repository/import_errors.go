@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/example/rms/internal/domain"
+)
+
+// importers re-run a single row of an entity through its import path; they
+// are used to retry rows recorded in import_errors.
+var importers = map[string]func(ctx context.Context, tx *sql.Tx, raw json.RawMessage) error{
+	"product":         retryAs(importProduct),
+	"menu_category":   retryAs(importMenuCategory),
+	"table":           retryAs(importTable),
+	"dish":            retryAs(importDish),
+	"dish_ingredient": retryAs(importDishIngredient),
+	"customer":        retryAs(importCustomer),
+	"employee":        retryAs(importEmployee),
+}
+
+// importPermissions lists entities whose retry needs more than import:create,
+// matching the batch-import route overrides.
+var importPermissions = map[string]string{
+	"employee": "employees:create",
+}
+
+// retryAs adapts an importer to raw JSON rows.
+func retryAs[T any](importRow importRowFunc[T]) func(ctx context.Context, tx *sql.Tx, raw json.RawMessage) error {
+	return func(ctx context.Context, tx *sql.Tx, raw json.RawMessage) error {
+		var row T
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return err
+		}
+		_, err := importRow(ctx, tx, row)
+		return err
+	}
+}
+
+const importErrorColumns = `id, created_at, entity, COALESCE(raw_data, 'null'::jsonb), error_message, status, resolved_at, resolved_by`
+
+var importErrorList = listSpec{
+	table:   "import_errors",
+	columns: importErrorColumns,
+	fields: map[string]listField{
+		"id":         {column: "id", kind: "bigint", sortable: true},
+		"entity":     {column: "entity", kind: "text"},
+		"status":     {column: "status", kind: "text"},
+		"created_at": {column: "created_at", kind: "timestamp", sortable: true},
+	},
+	defaultSort: "-id",
+}
+
+func (r *Repository) ListImportErrors(ctx context.Context, q ListQuery) (domain.Page, error) {
+	return listPage(ctx, r.DB, importErrorList, q, scanImportError)
+}
+
+func scanImportError(row rowScanner) (domain.ImportError, error) {
+	var e domain.ImportError
+	var raw []byte
+	var resolvedAt sql.NullTime
+	var resolvedBy sql.NullInt64
+	if err := row.Scan(&e.ID, &e.CreatedAt, &e.Entity, &raw, &e.ErrorMessage, &e.Status, &resolvedAt, &resolvedBy); err != nil {
+		return e, err
+	}
+	e.RawData = raw
+	if resolvedAt.Valid {
+		e.ResolvedAt = &resolvedAt.Time
+	}
+	if resolvedBy.Valid {
+		e.ResolvedBy = &resolvedBy.Int64
+	}
+	return e, nil
+}
+
+// RetryImportError re-imports an open row, using raw when given to correct
+// its data. A row that imports is marked resolved; one that fails again keeps
+// the corrected data and the new message, and ErrImportFailed is returned.
+func (r *Repository) RetryImportError(ctx context.Context, id int64, raw json.RawMessage) (domain.ImportError, error) {
+	var res domain.ImportError
+	var importErr error
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		e, err := scanImportError(tx.QueryRowContext(ctx, `SELECT `+importErrorColumns+` FROM import_errors WHERE id=$1 FOR UPDATE`, id))
+		if err != nil {
+			return err
+		}
+		if e.Status != "open" {
+			return fmt.Errorf("%w: import error %d is %s", ErrIllegalTransition, id, e.Status)
+		}
+		importer, ok := importers[e.Entity]
+		if !ok {
+			return fmt.Errorf("%w: no importer for %q", ErrImportFailed, e.Entity)
+		}
+		if permission, ok := importPermissions[e.Entity]; ok {
+			actor := actorID(ctx)
+			can, err := employeeCan(ctx, tx, actor.Int64, permission)
+			if err != nil {
+				return err
+			}
+			if !actor.Valid || !can {
+				return fmt.Errorf("%w: %s", ErrPermissionDenied, permission)
+			}
+		}
+		if len(raw) == 0 {
+			raw = e.RawData
+		}
+
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT import_retry`); err != nil {
+			return err
+		}
+		if importErr = importer(ctx, tx, raw); importErr != nil {
+			if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT import_retry`); err != nil {
+				return err
+			}
+			res, err = scanImportError(tx.QueryRowContext(ctx, `
+				UPDATE import_errors SET raw_data=$1::jsonb, error_message=$2
+				WHERE id=$3
+				RETURNING `+importErrorColumns, string(raw), importErr.Error(), id))
+			return err
+		}
+		res, err = scanImportError(tx.QueryRowContext(ctx, `
+			UPDATE import_errors SET raw_data=$1::jsonb, status='resolved', resolved_at=now(), resolved_by=$2
+			WHERE id=$3
+			RETURNING `+importErrorColumns, string(raw), actorID(ctx), id))
+		return err
+	})
+	if err == nil && importErr != nil {
+		err = fmt.Errorf("%w: %v", ErrImportFailed, importErr)
+	}
+	return res, err
+}
+
+// DismissImportErrors marks open rows as dismissed: the listed ids, or when
+// ids is empty, every open row of entity (if set) created before the cutoff
+// (if set). At least one criterion is required.
+func (r *Repository) DismissImportErrors(ctx context.Context, ids []int64, entity string, createdBefore *time.Time) (int64, error) {
+	if len(ids) == 0 && entity == "" && createdBefore == nil {
+		return 0, fmt.Errorf("%w: ids, entity or created_before is required", ErrInvalidListQuery)
+	}
+	var before sql.NullTime
+	if createdBefore != nil {
+		before = sql.NullTime{Time: *createdBefore, Valid: true}
+	}
+	res, err := r.exec(ctx, `
+		UPDATE import_errors SET status='dismissed', resolved_at=now(), resolved_by=$1
+		WHERE status='open'
+		  AND (COALESCE(cardinality($2::bigint[]), 0) = 0 OR id = ANY($2::bigint[]))
+		  AND ($3 = '' OR entity = $3)
+		  AND ($4::timestamp IS NULL OR created_at < $4)`,
+		actorID(ctx), pq.Array(ids), entity, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// actorID is the authenticated employee for resolved_by-style columns, or NULL.
+func actorID(ctx context.Context) sql.NullInt64 {
+	id, ok := EmployeeFromContext(ctx)
+	return sql.NullInt64{Int64: id, Valid: ok}
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/example/rms/internal/domain"
+)
+
+// importRowFunc writes one imported row and reports whether it created a new
+// record (true) or updated the one matching its natural key (false).
+type importRowFunc[T any] func(ctx context.Context, tx *sql.Tx, row T) (bool, error)
+
+// importBatch imports rows in one transaction, each behind its own savepoint:
+// a failing row is rolled back alone and recorded in import_errors, and the
+// rows that succeed are committed. The counts reflect what was committed.
+func importBatch[T any](ctx context.Context, r *Repository, entity string, rows []T, importRow importRowFunc[T]) (domain.ImportResult, error) {
+	res := domain.ImportResult{Total: len(rows), ErrorIDs: []int64{}}
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		for _, row := range rows {
+			if _, err := tx.ExecContext(ctx, `SAVEPOINT import_row`); err != nil {
+				return err
+			}
+			created, rowErr := importRow(ctx, tx, row)
+			if rowErr != nil {
+				if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT import_row`); err != nil {
+					return err
+				}
+				id, err := logErr(ctx, tx, entity, row, rowErr)
+				if err != nil {
+					return err
+				}
+				res.Failed++
+				res.ErrorIDs = append(res.ErrorIDs, id)
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT import_row`); err != nil {
+				return err
+			}
+			if created {
+				res.Inserted++
+			} else {
+				res.Updated++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.ImportResult{}, err
+	}
+	return res, nil
+}
+
+// Batch import products
+func (r *Repository) BatchImportProducts(ctx context.Context, products []domain.Product) (domain.ImportResult, error) {
+	return importBatch(ctx, r, "product", products, importProduct)
+}
+
+// importProduct upserts one imported product by name.
+func importProduct(ctx context.Context, tx *sql.Tx, p domain.Product) (bool, error) {
+	if p.Name == "" || p.Unit == "" {
+		return false, errors.New("name and unit are required")
+	}
+	return upsertCreated(tx.QueryRowContext(ctx, `
+		INSERT INTO products(name, unit, cost_price, is_available)
+		VALUES ($1,$2,$3,$4)
+		ON CONFLICT (name) DO UPDATE SET unit=EXCLUDED.unit, cost_price=EXCLUDED.cost_price,
+			is_available=COALESCE((SELECT s.quantity > 0 FROM product_stock s WHERE s.product_id = products.id), EXCLUDED.is_available)
+		RETURNING xmax = 0`,
+		p.Name, p.Unit, p.CostPrice, p.IsAvailable))
+}
+
+// upsertCreated scans the "xmax = 0" flag returned by an upsert, which is
+// true when the row was inserted rather than updated.
+func upsertCreated(row *sql.Row) (bool, error) {
+	var created bool
+	err := row.Scan(&created)
+	return created, err
+}
+
+// logErr records a failed row in import_errors and returns its id.
+func logErr(ctx context.Context, tx *sql.Tx, entity string, raw interface{}, err error) (int64, error) {
+	var id int64
+	scanErr := tx.QueryRowContext(ctx, `
+		INSERT INTO import_errors(entity, raw_data, error_message)
+		VALUES ($1, to_jsonb($2::json), $3)
+		RETURNING id`,
+		entity, rawAsJSON(raw), err.Error()).Scan(&id)
+	return id, scanErr
+}
+
+func rawAsJSON(raw interface{}) string {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+func (r *Repository) BatchImportMenuCategories(ctx context.Context, categories []domain.MenuCategoryImport) (domain.ImportResult, error) {
+	return importBatch(ctx, r, "menu_category", categories, importMenuCategory)
+}
+
+// importMenuCategory upserts one imported menu category by name.
+func importMenuCategory(ctx context.Context, tx *sql.Tx, c domain.MenuCategoryImport) (bool, error) {
+	if c.Name == "" {
+		return false, errors.New("name is required")
+	}
+	return upsertCreated(tx.QueryRowContext(ctx, `
+		INSERT INTO menu_categories(name, description, sort_order, is_active)
+		VALUES ($1, NULLIF($2,''), $3, COALESCE($4, TRUE))
+		ON CONFLICT (name) DO UPDATE SET description=EXCLUDED.description, sort_order=EXCLUDED.sort_order, is_active=EXCLUDED.is_active
+		RETURNING xmax = 0`,
+		c.Name, c.Description, c.SortOrder, c.IsActive))
+}
+
+func (r *Repository) BatchImportTables(ctx context.Context, tables []domain.TableImport) (domain.ImportResult, error) {
+	return importBatch(ctx, r, "table", tables, importTable)
+}
+
+// importTable upserts one imported restaurant table by table number.
+func importTable(ctx context.Context, tx *sql.Tx, t domain.TableImport) (bool, error) {
+	if t.TableNumber <= 0 || t.Seats <= 0 {
+		return false, errors.New("positive table_number and seats are required")
+	}
+	return upsertCreated(tx.QueryRowContext(ctx, `
+		INSERT INTO restaurant_tables(table_number, seats, is_active, description)
+		VALUES ($1, $2, COALESCE($3, TRUE), NULLIF($4,''))
+		ON CONFLICT (table_number) DO UPDATE SET seats=EXCLUDED.seats, is_active=EXCLUDED.is_active, description=EXCLUDED.description
+		RETURNING xmax = 0`,
+		t.TableNumber, t.Seats, t.IsActive, t.Description))
+}
+
+func (r *Repository) BatchImportDishes(ctx context.Context, dishes []domain.DishImport) (domain.ImportResult, error) {
+	return importBatch(ctx, r, "dish", dishes, importDish)
+}
+
+// importDish upserts one imported dish by category name and dish name.
+func importDish(ctx context.Context, tx *sql.Tx, d domain.DishImport) (bool, error) {
+	if d.Category == "" || d.Name == "" || d.Price <= 0 || d.CookTimeMinutes <= 0 {
+		return false, errors.New("category, name, positive price and cook_time_minutes are required")
+	}
+	categoryID, err := lookupID(ctx, tx, fmt.Sprintf("unknown category %q", d.Category),
+		`SELECT id FROM menu_categories WHERE name=$1`, d.Category)
+	if err != nil {
+		return false, err
+	}
+	return upsertCreated(tx.QueryRowContext(ctx, `
+		INSERT INTO dishes(category_id, name, price, cook_time_minutes, is_active, description)
+		VALUES ($1, $2, $3, $4, COALESCE($5, TRUE), NULLIF($6,''))
+		ON CONFLICT (category_id, name) DO UPDATE SET price=EXCLUDED.price, cook_time_minutes=EXCLUDED.cook_time_minutes, is_active=EXCLUDED.is_active, description=EXCLUDED.description
+		RETURNING xmax = 0`,
+		categoryID, d.Name, d.Price, d.CookTimeMinutes, d.IsActive, d.Description))
+}
+
+func (r *Repository) BatchImportDishIngredients(ctx context.Context, ingredients []domain.DishIngredientImport) (domain.ImportResult, error) {
+	return importBatch(ctx, r, "dish_ingredient", ingredients, importDishIngredient)
+}
+
+// importDishIngredient upserts one recipe line by dish and product name.
+func importDishIngredient(ctx context.Context, tx *sql.Tx, di domain.DishIngredientImport) (bool, error) {
+	if di.Dish == "" || di.Product == "" || di.Quantity <= 0 {
+		return false, errors.New("dish, product and positive quantity are required")
+	}
+	dishIDs, err := queryIDs(ctx, tx, `
+		SELECT d.id FROM dishes d
+		JOIN menu_categories c ON c.id = d.category_id
+		WHERE d.name=$1 AND ($2 = '' OR c.name = $2)`, di.Dish, di.Category)
+	if err != nil {
+		return false, err
+	}
+	switch len(dishIDs) {
+	case 0:
+		return false, fmt.Errorf("unknown dish %q", di.Dish)
+	case 1:
+	default:
+		return false, fmt.Errorf("dish %q exists in several categories, set category", di.Dish)
+	}
+	productID, err := lookupID(ctx, tx, fmt.Sprintf("unknown product %q", di.Product),
+		`SELECT id FROM products WHERE name=$1`, di.Product)
+	if err != nil {
+		return false, err
+	}
+	return upsertCreated(tx.QueryRowContext(ctx, `
+		INSERT INTO dish_ingredients(dish_id, product_id, quantity)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (dish_id, product_id) DO UPDATE SET quantity=EXCLUDED.quantity
+		RETURNING xmax = 0`,
+		dishIDs[0], productID, di.Quantity))
+}
+
+func (r *Repository) BatchImportCustomers(ctx context.Context, customers []domain.Customer) (domain.ImportResult, error) {
+	return importBatch(ctx, r, "customer", customers, importCustomer)
+}
+
+// importCustomer updates the customer with the same normalized phone number
+// or creates a new one. phone_normalized is not unique, so a number shared by
+// several customers is reported instead of overwriting all of them.
+func importCustomer(ctx context.Context, tx *sql.Tx, c domain.Customer) (bool, error) {
+	if c.FullName == "" || c.Phone == "" {
+		return false, errors.New("full_name and phone are required")
+	}
+	ids, err := queryIDs(ctx, tx, `SELECT id FROM customers WHERE phone_normalized=$1 ORDER BY id FOR UPDATE`, normalizePhone(c.Phone))
+	if err != nil {
+		return false, err
+	}
+	switch len(ids) {
+	case 0:
+	case 1:
+		_, err = tx.ExecContext(ctx, `UPDATE customers SET full_name=$1, email=COALESCE($2, email) WHERE id=$3`,
+			c.FullName, c.Email, ids[0])
+		return false, err
+	default:
+		return false, fmt.Errorf("phone %s matches customers %v", c.Phone, ids)
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO customers(full_name, phone, email) VALUES ($1, $2, $3)`,
+		c.FullName, c.Phone, c.Email)
+	return err == nil, err
+}
+
+func (r *Repository) BatchImportEmployees(ctx context.Context, employees []domain.EmployeeImport) (domain.ImportResult, error) {
+	return importBatch(ctx, r, "employee", employees, importEmployee)
+}
+
+// importEmployee upserts one imported employee by phone; the role is resolved by name.
+func importEmployee(ctx context.Context, tx *sql.Tx, e domain.EmployeeImport) (bool, error) {
+	if e.FullName == "" || e.Phone == "" || e.Role == "" {
+		return false, errors.New("full_name, phone and role are required")
+	}
+	roleID, err := lookupID(ctx, tx, fmt.Sprintf("unknown role %q", e.Role),
+		`SELECT id FROM roles WHERE name=$1`, e.Role)
+	if err != nil {
+		return false, err
+	}
+	return upsertCreated(tx.QueryRowContext(ctx, `
+		INSERT INTO employees(full_name, phone, email, role_id, hired_at, is_active)
+		VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5,'')::date, current_date), COALESCE($6, TRUE))
+		ON CONFLICT (phone) DO UPDATE SET full_name=EXCLUDED.full_name, email=COALESCE(EXCLUDED.email, employees.email),
+			role_id=EXCLUDED.role_id, hired_at=COALESCE(NULLIF($5,'')::date, employees.hired_at), is_active=EXCLUDED.is_active
+		RETURNING xmax = 0`,
+		e.FullName, e.Phone, e.Email, roleID, e.HiredAt, e.IsActive))
+}
+
+// queryIDs returns the ids selected by query.
+func queryIDs(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// lookupID resolves a natural key to an id, failing with notFound when no row matches.
+func lookupID(ctx context.Context, tx *sql.Tx, notFound, query string, args ...interface{}) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, query, args...).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, errors.New(notFound)
+	}
+	return id, err
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/rms/internal/domain"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+// ListQuery is a list request shared by the list endpoints: field filters, a
+// sort field ("-" prefix for descending), a cursor from the previous page and
+// the page size.
+type ListQuery struct {
+	Filters []ListFilter
+	Sort    string
+	Cursor  string
+	Limit   int
+}
+
+// ListFilter compares a field with Op: "eq" and "in" take any field, "gte" and
+// "lte" take numbers, dates and timestamps. Only "in" uses more than one value.
+type ListFilter struct {
+	Field  string
+	Op     string
+	Values []string
+}
+
+// listField describes a filterable column. kind is the PostgreSQL type the
+// values are cast to; only NOT NULL columns may be sortable.
+type listField struct {
+	column   string
+	kind     string
+	sortable bool
+}
+
+// listFilterFunc builds a condition for a filter with custom semantics (e.g.
+// phone normalization); arg binds a value and returns its placeholder.
+type listFilterFunc func(value string, arg func(interface{}) string) (string, error)
+
+// listSpec describes how an entity is listed.
+type listSpec struct {
+	table       string
+	columns     string
+	fields      map[string]listField
+	custom      map[string]listFilterFunc
+	defaultSort string
+}
+
+var rangeKinds = map[string]bool{"bigint": true, "int": true, "numeric": true, "date": true, "timestamp": true}
+
+// listCursor is the keyset position after the last row of a page.
+type listCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+// cursorRow appends the sort key and id selected after the entity columns to
+// the scan destinations, so entity scan functions can be reused unchanged.
+type cursorRow struct {
+	rows  *sql.Rows
+	key   *string
+	keyID *int64
+}
+
+func (c cursorRow) Scan(dest ...interface{}) error {
+	return c.rows.Scan(append(dest, c.key, c.keyID)...)
+}
+
+// listPage runs q against spec using keyset pagination on (sort field, id).
+func listPage[T any](ctx context.Context, db *sql.DB, spec listSpec, q ListQuery, scan func(rowScanner) (T, error)) (domain.Page, error) {
+	var page domain.Page
+	items := []T{}
+	if q.Limit <= 0 || q.Limit > maxListLimit {
+		q.Limit = defaultListLimit
+	}
+	if q.Sort == "" {
+		q.Sort = spec.defaultSort
+	}
+	desc := strings.HasPrefix(q.Sort, "-")
+	sortField, ok := spec.fields[strings.TrimPrefix(q.Sort, "-")]
+	if !ok || !sortField.sortable {
+		return page, fmt.Errorf("%w: cannot sort by %q", ErrInvalidListQuery, strings.TrimPrefix(q.Sort, "-"))
+	}
+
+	var where []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	for _, f := range q.Filters {
+		cond, err := spec.condition(f, arg)
+		if err != nil {
+			return page, err
+		}
+		where = append(where, cond)
+	}
+	if q.Cursor != "" {
+		c, err := decodeListCursor(q.Cursor)
+		if err != nil {
+			return page, err
+		}
+		if c.Sort != q.Sort {
+			return page, fmt.Errorf("%w: cursor was issued for sort %q", ErrInvalidCursor, c.Sort)
+		}
+		cmp := ">"
+		if desc {
+			cmp = "<"
+		}
+		where = append(where, fmt.Sprintf("(%s, id) %s (%s::%s, %s)", sortField.column, cmp, arg(c.Value), sortField.kind, arg(c.ID)))
+	}
+
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	query := fmt.Sprintf(`SELECT %s, %s::text, id FROM %s`, spec.columns, sortField.column, spec.table)
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	// One extra row tells whether there is a next page.
+	query += fmt.Sprintf(` ORDER BY %s %s, id %s LIMIT %s`, sortField.column, dir, dir, arg(q.Limit+1))
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+	var last listCursor
+	for rows.Next() {
+		if len(items) == q.Limit {
+			page.NextCursor = encodeListCursor(last)
+			break
+		}
+		last = listCursor{Sort: q.Sort}
+		item, err := scan(cursorRow{rows: rows, key: &last.Value, keyID: &last.ID})
+		if err != nil {
+			return page, err
+		}
+		items = append(items, item)
+	}
+	page.Items = items
+	return page, rows.Err()
+}
+
+func (spec listSpec) condition(f ListFilter, arg func(interface{}) string) (string, error) {
+	if len(f.Values) == 0 {
+		return "", fmt.Errorf("%w: %s needs a value", ErrInvalidListQuery, f.Field)
+	}
+	if build, ok := spec.custom[f.Field]; ok {
+		if f.Op != "eq" {
+			return "", fmt.Errorf("%w: %s only supports eq", ErrInvalidListQuery, f.Field)
+		}
+		return build(f.Values[0], arg)
+	}
+	field, ok := spec.fields[f.Field]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown filter %q", ErrInvalidListQuery, f.Field)
+	}
+	switch f.Op {
+	case "eq":
+		return fmt.Sprintf("%s = %s::%s", field.column, arg(f.Values[0]), field.kind), nil
+	case "in":
+		placeholders := make([]string, len(f.Values))
+		for i, v := range f.Values {
+			placeholders[i] = arg(v) + "::" + field.kind
+		}
+		return fmt.Sprintf("%s IN (%s)", field.column, strings.Join(placeholders, ", ")), nil
+	case "gte", "lte":
+		if !rangeKinds[field.kind] {
+			return "", fmt.Errorf("%w: %s does not support %s", ErrInvalidListQuery, f.Field, f.Op)
+		}
+		cmp := ">="
+		if f.Op == "lte" {
+			cmp = "<="
+		}
+		return fmt.Sprintf("%s %s %s::%s", field.column, cmp, arg(f.Values[0]), field.kind), nil
+	default:
+		return "", fmt.Errorf("%w: unknown operator %q", ErrInvalidListQuery, f.Op)
+	}
+}
+
+func encodeListCursor(c listCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeListCursor(s string) (listCursor, error) {
+	var c listCursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return c, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestListCursorRoundTrip(t *testing.T) {
+	tests := []listCursor{
+		{Sort: "id", Value: "42", ID: 42},
+		{Sort: "-created_at", Value: "2024-03-01 19:30:00", ID: 7},
+		{Sort: "name", Value: `Борщ "домашний"`, ID: 3},
+		{Sort: "name", Value: "", ID: 1},
+	}
+	for _, c := range tests {
+		got, err := decodeListCursor(encodeListCursor(c))
+		if err != nil {
+			t.Fatalf("decode(encode(%+v)): %v", c, err)
+		}
+		if got != c {
+			t.Errorf("decode(encode(%+v)) = %+v", c, got)
+		}
+	}
+}
+
+func TestDecodeListCursorRejectsGarbage(t *testing.T) {
+	tests := map[string]string{
+		"not base64":     "%%%",
+		"padded base64":  base64.URLEncoding.EncodeToString([]byte(`{"s":"id"}`)),
+		"not json":       base64.RawURLEncoding.EncodeToString([]byte("id:42")),
+		"wrong id type":  base64.RawURLEncoding.EncodeToString([]byte(`{"s":"id","v":"1","id":"x"}`)),
+		"truncated json": base64.RawURLEncoding.EncodeToString([]byte(`{"s":"id"`)),
+	}
+	for name, cursor := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := decodeListCursor(cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("err = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestListSpecCondition(t *testing.T) {
+	spec := listSpec{
+		fields: map[string]listField{
+			"status":     {column: "status", kind: "text"},
+			"total":      {column: "total_amount", kind: "numeric"},
+			"created_at": {column: "created_at", kind: "timestamp"},
+		},
+		custom: map[string]listFilterFunc{
+			"phone": func(v string, arg func(interface{}) string) (string, error) {
+				return "phone_normalized LIKE " + arg(v+"%"), nil
+			},
+		},
+	}
+	tests := []struct {
+		name     string
+		filter   ListFilter
+		want     string
+		wantArgs []interface{}
+		wantErr  error
+	}{
+		{
+			name:     "eq casts to the column type",
+			filter:   ListFilter{Field: "status", Op: "eq", Values: []string{"new"}},
+			want:     "status = $1::text",
+			wantArgs: []interface{}{"new"},
+		},
+		{
+			name:     "in binds every value",
+			filter:   ListFilter{Field: "status", Op: "in", Values: []string{"new", "in_progress"}},
+			want:     "status IN ($1::text, $2::text)",
+			wantArgs: []interface{}{"new", "in_progress"},
+		},
+		{
+			name:     "gte on a number",
+			filter:   ListFilter{Field: "total", Op: "gte", Values: []string{"100"}},
+			want:     "total_amount >= $1::numeric",
+			wantArgs: []interface{}{"100"},
+		},
+		{
+			name:     "lte on a timestamp",
+			filter:   ListFilter{Field: "created_at", Op: "lte", Values: []string{"2024-03-01"}},
+			want:     "created_at <= $1::timestamp",
+			wantArgs: []interface{}{"2024-03-01"},
+		},
+		{
+			name:     "custom filter",
+			filter:   ListFilter{Field: "phone", Op: "eq", Values: []string{"7912"}},
+			want:     "phone_normalized LIKE $1",
+			wantArgs: []interface{}{"7912%"},
+		},
+		{
+			name:    "range on text",
+			filter:  ListFilter{Field: "status", Op: "gte", Values: []string{"a"}},
+			wantErr: ErrInvalidListQuery,
+		},
+		{
+			name:    "custom filter only supports eq",
+			filter:  ListFilter{Field: "phone", Op: "in", Values: []string{"7912"}},
+			wantErr: ErrInvalidListQuery,
+		},
+		{
+			name:    "unknown field",
+			filter:  ListFilter{Field: "password", Op: "eq", Values: []string{"x"}},
+			wantErr: ErrInvalidListQuery,
+		},
+		{
+			name:    "unknown operator",
+			filter:  ListFilter{Field: "status", Op: "like", Values: []string{"n%"}},
+			wantErr: ErrInvalidListQuery,
+		},
+		{
+			name:    "missing value",
+			filter:  ListFilter{Field: "status", Op: "eq"},
+			wantErr: ErrInvalidListQuery,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args []interface{}
+			arg := func(v interface{}) string {
+				args = append(args, v)
+				return "$" + strconv.Itoa(len(args))
+			}
+			got, err := spec.condition(tt.filter, arg)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("condition = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
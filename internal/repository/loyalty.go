@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// loyaltyLevel returns the highest VIP level whose spend threshold is reached.
+func (r *Repository) loyaltyLevel(spent float64) int {
+	level := 0
+	for i, threshold := range r.Cfg.LoyaltyThresholds {
+		if toCents(spent) >= toCents(threshold) {
+			level = i + 1
+		}
+	}
+	return level
+}
+
+func (r *Repository) levelDiscount(level int) float64 {
+	if level < 0 || level >= len(r.Cfg.LoyaltyDiscounts) {
+		return 0
+	}
+	return r.Cfg.LoyaltyDiscounts[level]
+}
+
+// customerDiscount returns the discount of the customer's current VIP level.
+func (r *Repository) customerDiscount(ctx context.Context, tx *sql.Tx, customerID int64) (float64, error) {
+	var level int
+	if err := tx.QueryRowContext(ctx, `SELECT vip_level FROM customers WHERE id=$1`, customerID).Scan(&level); err != nil {
+		return 0, err
+	}
+	return r.levelDiscount(level), nil
+}
+
+// refreshCustomerLevel recalculates vip_level of the order's customer from
+// get_customer_total_spent. It runs whenever money on the order changes hands.
+func (r *Repository) refreshCustomerLevel(ctx context.Context, tx *sql.Tx, orderID int64) error {
+	var customerID sql.NullInt64
+	if err := tx.QueryRowContext(ctx, `SELECT customer_id FROM orders WHERE id=$1`, orderID).Scan(&customerID); err != nil {
+		return err
+	}
+	if !customerID.Valid {
+		return nil
+	}
+	var spent float64
+	if err := tx.QueryRowContext(ctx, `SELECT get_customer_total_spent($1)`, customerID.Int64).Scan(&spent); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `UPDATE customers SET vip_level=$1 WHERE id=$2 AND vip_level<>$1`, r.loyaltyLevel(spent), customerID.Int64)
+	return err
+}
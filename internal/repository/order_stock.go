@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/example/rms/internal/domain"
+)
+
+// orderStatusRank orders the forward path of an order; cancellation is handled separately.
+var orderStatusRank = map[string]int{"new": 0, "in_progress": 1, "closed": 2}
+
+// syncOrderStock deducts ingredients once the order reaches the configured status
+// and puts them back when a deducted order is cancelled. The order row must be
+// locked by the caller. Returned warnings come from the stock shortage policy.
+func (r *Repository) syncOrderStock(ctx context.Context, tx *sql.Tx, orderID int64, status string, deducted bool) ([]string, error) {
+	switch {
+	case status == "cancelled" && deducted:
+		return nil, r.restoreOrderStock(ctx, tx, orderID)
+	case status != "cancelled" && !deducted && orderStatusRank[status] >= orderStatusRank[r.Cfg.StockDeductOn]:
+		return r.deductOrderStock(ctx, tx, orderID)
+	}
+	return nil, nil
+}
+
+type productQuantity struct {
+	productID int64
+	quantity  float64
+}
+
+func queryProductQuantities(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]productQuantity, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []productQuantity
+	for rows.Next() {
+		var q productQuantity
+		if err := rows.Scan(&q.productID, &q.quantity); err != nil {
+			return nil, err
+		}
+		res = append(res, q)
+	}
+	return res, rows.Err()
+}
+
+func (r *Repository) deductOrderStock(ctx context.Context, tx *sql.Tx, orderID int64) ([]string, error) {
+	// Products are locked in id order so concurrent orders cannot deadlock.
+	needs, err := queryProductQuantities(ctx, tx, `
+		SELECT di.product_id, SUM(di.quantity * oi.quantity)
+		FROM order_items oi
+		JOIN dish_ingredients di ON di.dish_id = oi.dish_id
+		WHERE oi.order_id = $1
+		GROUP BY di.product_id
+		ORDER BY di.product_id`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := r.consumeStock(ctx, tx, orderID, needs)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE orders SET stock_deducted=TRUE WHERE id=$1`, orderID)
+	return warnings, err
+}
+
+// consumeStock writes consumption movements for the order, applying the stock
+// shortage policy. needs must be sorted by product id.
+func (r *Repository) consumeStock(ctx context.Context, tx *sql.Tx, orderID int64, needs []productQuantity) ([]string, error) {
+	var warnings []string
+	for _, need := range needs {
+		current, err := lockStock(ctx, tx, need.productID)
+		if err != nil {
+			return nil, err
+		}
+		m := domain.StockMovement{
+			ProductID:     need.productID,
+			MovementType:  "consumption",
+			QuantityDelta: -need.quantity,
+			QuantityAfter: roundQuantity(current - need.quantity),
+			Reason:        fmt.Sprintf("order #%d", orderID),
+			OrderID:       &orderID,
+		}
+		if m.QuantityAfter < 0 {
+			switch r.Cfg.StockShortagePolicy {
+			case "allow_negative":
+				warnings = append(warnings, fmt.Sprintf("product %d stock is negative: %.3f", need.productID, m.QuantityAfter))
+			case "clamp":
+				warnings = append(warnings, fmt.Sprintf("product %d is short by %.3f, stock clamped at zero", need.productID, -m.QuantityAfter))
+				m.QuantityDelta = -current
+				m.QuantityAfter = 0
+			default:
+				return nil, fmt.Errorf("%w: product %d needs %.3f, %.3f in stock", ErrInsufficientStock, need.productID, need.quantity, current)
+			}
+		}
+		if m.QuantityDelta == 0 {
+			continue
+		}
+		if err := writeStockMovement(ctx, tx, &m); err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
+}
+
+// restoreOrderStock reverses exactly what the ledger says was taken for the order,
+// which also covers quantities reduced by the clamp policy.
+func (r *Repository) restoreOrderStock(ctx context.Context, tx *sql.Tx, orderID int64) error {
+	taken, err := queryProductQuantities(ctx, tx, `
+		SELECT product_id, -SUM(quantity_delta)
+		FROM stock_movements
+		WHERE order_id = $1 AND movement_type IN ('consumption','return')
+		GROUP BY product_id
+		HAVING SUM(quantity_delta) <> 0
+		ORDER BY product_id`, orderID)
+	if err != nil {
+		return err
+	}
+	if err := returnStock(ctx, tx, orderID, taken, fmt.Sprintf("order #%d cancelled", orderID)); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE orders SET stock_deducted=FALSE WHERE id=$1`, orderID)
+	return err
+}
+
+// returnStock writes return movements for the order. quantities must be sorted
+// by product id.
+func returnStock(ctx context.Context, tx *sql.Tx, orderID int64, quantities []productQuantity, reason string) error {
+	for _, q := range quantities {
+		current, err := lockStock(ctx, tx, q.productID)
+		if err != nil {
+			return err
+		}
+		m := domain.StockMovement{
+			ProductID:     q.productID,
+			MovementType:  "return",
+			QuantityDelta: q.quantity,
+			QuantityAfter: roundQuantity(current + q.quantity),
+			Reason:        reason,
+			OrderID:       &orderID,
+		}
+		if err := writeStockMovement(ctx, tx, &m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncOrderItemStock keeps an already deducted order in step with an item
+// change: delta more portions of the dish are consumed under the shortage
+// policy, fewer portions are put back, never more than the ledger says the
+// order took.
+func (r *Repository) syncOrderItemStock(ctx context.Context, tx *sql.Tx, orderID, dishID int64, delta int) ([]string, error) {
+	if delta == 0 {
+		return nil, nil
+	}
+	portions := delta
+	if portions < 0 {
+		portions = -portions
+	}
+	needs, err := queryProductQuantities(ctx, tx, `
+		SELECT product_id, quantity * $2
+		FROM dish_ingredients
+		WHERE dish_id = $1
+		ORDER BY product_id`, dishID, portions)
+	if err != nil {
+		return nil, err
+	}
+	if delta > 0 {
+		return r.consumeStock(ctx, tx, orderID, needs)
+	}
+
+	taken, err := queryProductQuantities(ctx, tx, `
+		SELECT product_id, -SUM(quantity_delta)
+		FROM stock_movements
+		WHERE order_id = $1 AND movement_type IN ('consumption','return')
+		GROUP BY product_id`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	left := make(map[int64]float64, len(taken))
+	for _, t := range taken {
+		left[t.productID] = t.quantity
+	}
+	var back []productQuantity
+	for _, need := range needs {
+		if q := roundQuantity(math.Min(need.quantity, left[need.productID])); q > 0 {
+			back = append(back, productQuantity{need.productID, q})
+		}
+	}
+	return nil, returnStock(ctx, tx, orderID, back, fmt.Sprintf("order #%d item removed", orderID))
+}
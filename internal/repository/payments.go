@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/example/rms/internal/domain"
+)
+
+// Payments
+func (r *Repository) ListPayments(ctx context.Context, orderID int64) ([]domain.Payment, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT id, order_id, amount, method, paid_at, status FROM payments WHERE order_id=$1 ORDER BY id`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Payment
+	for rows.Next() {
+		var p domain.Payment
+		if err := rows.Scan(&p.ID, &p.OrderID, &p.Amount, &p.Method, &p.PaidAt, &p.Status); err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, rows.Err()
+}
+
+// GetOrderBalance returns the order total computed from order_items next to its
+// payments. Refunds do not reopen the bill, so they are reported separately.
+func (r *Repository) GetOrderBalance(ctx context.Context, orderID int64) (domain.OrderBalance, error) {
+	b := domain.OrderBalance{OrderID: orderID}
+	var err error
+	if b.Total, err = orderTotal(ctx, r.DB, orderID); err != nil {
+		return b, err
+	}
+	if b.Payments, err = r.ListPayments(ctx, orderID); err != nil {
+		return b, err
+	}
+	var paid, pending int64
+	for _, p := range b.Payments {
+		switch p.Status {
+		case "paid", "refunded":
+			paid += toCents(p.Amount)
+		case "pending":
+			pending += toCents(p.Amount)
+		}
+	}
+	b.Paid = fromCents(paid)
+	b.Pending = fromCents(pending)
+	b.Outstanding = fromCents(max64(toCents(b.Total)-paid, 0))
+	b.Refunded, err = orderRefunded(ctx, r.DB, orderID)
+	return b, err
+}
+
+// CreatePayment adds a payment to the order and closes the order once it is fully
+// paid. The amount may not exceed what is still due on the order total. A paid
+// payment also recalculates the customer's VIP level. Cancelled orders take no payments.
+func (r *Repository) CreatePayment(ctx context.Context, p *domain.Payment) ([]string, error) {
+	var warnings []string
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		if err := lockOrder(ctx, tx, p.OrderID); err != nil {
+			return err
+		}
+		due, err := amountDue(ctx, tx, p.OrderID)
+		if err != nil {
+			return err
+		}
+		if toCents(p.Amount) > due {
+			return fmt.Errorf("%w: %.2f left to pay on order %d", ErrPaymentExceedsDue, fromCents(due), p.OrderID)
+		}
+		if err := insertPayment(ctx, tx, p); err != nil {
+			return err
+		}
+		if p.Status != "paid" {
+			return nil
+		}
+		if err := r.refreshCustomerLevel(ctx, tx, p.OrderID); err != nil {
+			return err
+		}
+		warnings, err = r.closeOrderIfPaid(ctx, tx, p.OrderID)
+		return err
+	})
+	return warnings, err
+}
+
+// PayPayment marks a pending payment (e.g. one share of a split bill) as paid.
+func (r *Repository) PayPayment(ctx context.Context, id int64, method string) (domain.Payment, []string, error) {
+	var p domain.Payment
+	var warnings []string
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		var orderID int64
+		if err := tx.QueryRowContext(ctx, `SELECT order_id FROM payments WHERE id=$1`, id).Scan(&orderID); err != nil {
+			return err
+		}
+		if err := lockOrder(ctx, tx, orderID); err != nil {
+			return err
+		}
+		err := tx.QueryRowContext(ctx, `
+			UPDATE payments SET status='paid', paid_at=now(), method=COALESCE(NULLIF($1,''), method)
+			WHERE id=$2 AND status='pending'
+			RETURNING id, order_id, amount, method, paid_at, status`, method, id).
+			Scan(&p.ID, &p.OrderID, &p.Amount, &p.Method, &p.PaidAt, &p.Status)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: payment %d is not pending", ErrIllegalTransition, id)
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.refreshCustomerLevel(ctx, tx, orderID); err != nil {
+			return err
+		}
+		warnings, err = r.closeOrderIfPaid(ctx, tx, orderID)
+		return err
+	})
+	return p, warnings, err
+}
+
+// DeletePayment removes a pending payment; paid payments are only undone by refunds.
+func (r *Repository) DeletePayment(ctx context.Context, id int64) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		var status string
+		if err := tx.QueryRowContext(ctx, `SELECT status FROM payments WHERE id=$1 FOR UPDATE`, id).Scan(&status); err != nil {
+			return err
+		}
+		if status != "pending" {
+			return fmt.Errorf("%w: payment %d is %s, refund it instead", ErrIllegalTransition, id, status)
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM payments WHERE id=$1`, id)
+		return err
+	})
+}
+
+// SplitOrderBill divides the outstanding part of the bill into pending payments.
+func (r *Repository) SplitOrderBill(ctx context.Context, orderID int64, split domain.BillSplit) ([]domain.Payment, error) {
+	var res []domain.Payment
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		if err := lockOrder(ctx, tx, orderID); err != nil {
+			return err
+		}
+		outstanding, err := amountDue(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+		if outstanding <= 0 {
+			return fmt.Errorf("%w: order %d has nothing left to split", ErrInvalidSplit, orderID)
+		}
+
+		shares, err := splitShares(ctx, tx, orderID, outstanding, split)
+		if err != nil {
+			return err
+		}
+		for _, share := range shares {
+			p := domain.Payment{OrderID: orderID, Amount: fromCents(share.cents), Method: share.method, Status: "pending"}
+			if err := insertPayment(ctx, tx, &p); err != nil {
+				return err
+			}
+			res = append(res, p)
+		}
+		return nil
+	})
+	return res, err
+}
+
+type billShare struct {
+	cents  int64
+	method string
+}
+
+func splitShares(ctx context.Context, tx *sql.Tx, orderID, outstanding int64, split domain.BillSplit) ([]billShare, error) {
+	var shares []billShare
+	switch split.Mode {
+	case "even":
+		if split.Parts < 2 {
+			return nil, fmt.Errorf("%w: even split needs at least 2 parts", ErrInvalidSplit)
+		}
+		// The first parts absorb the remainder so the shares add up to the exact amount.
+		base, rem := outstanding/int64(split.Parts), outstanding%int64(split.Parts)
+		for i := 0; i < split.Parts; i++ {
+			cents := base
+			if int64(i) < rem {
+				cents++
+			}
+			shares = append(shares, billShare{cents: cents, method: split.Method})
+		}
+	case "items":
+		prices, err := orderItemAmounts(ctx, tx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		used := make(map[int64]bool)
+		var sum int64
+		for _, g := range split.Groups {
+			var cents int64
+			for _, itemID := range g.ItemIDs {
+				price, ok := prices[itemID]
+				if !ok {
+					return nil, fmt.Errorf("%w: item %d does not belong to order %d", ErrInvalidSplit, itemID, orderID)
+				}
+				if used[itemID] {
+					return nil, fmt.Errorf("%w: item %d is in more than one group", ErrInvalidSplit, itemID)
+				}
+				used[itemID] = true
+				cents += price
+			}
+			sum += cents
+			shares = append(shares, billShare{cents: cents, method: g.Method})
+		}
+		// Spreading the order discount and service charge over items can
+		// overshoot by a cent per item; take that off the last group.
+		if over := sum - outstanding; over > 0 && over <= int64(len(used)) && len(shares) > 0 {
+			shares[len(shares)-1].cents -= over
+			sum = outstanding
+		}
+		if sum > outstanding {
+			return nil, fmt.Errorf("%w: selected items exceed the outstanding %.2f", ErrInvalidSplit, fromCents(outstanding))
+		}
+	case "custom":
+		var sum int64
+		for _, sh := range split.Shares {
+			cents := toCents(sh.Amount)
+			sum += cents
+			shares = append(shares, billShare{cents: cents, method: sh.Method})
+		}
+		if sum > outstanding {
+			return nil, fmt.Errorf("%w: shares exceed the outstanding %.2f", ErrInvalidSplit, fromCents(outstanding))
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown mode %q", ErrInvalidSplit, split.Mode)
+	}
+
+	for _, sh := range shares {
+		if sh.cents <= 0 {
+			return nil, fmt.Errorf("%w: every share must be positive", ErrInvalidSplit)
+		}
+		if sh.method == "" {
+			return nil, fmt.Errorf("%w: every share needs a payment method", ErrInvalidSplit)
+		}
+	}
+	return shares, nil
+}
+
+// closeOrderIfPaid closes the order once paid payments cover its total. A stock
+// shortage that blocks closing does not undo the payment; it becomes a warning.
+func (r *Repository) closeOrderIfPaid(ctx context.Context, tx *sql.Tx, orderID int64) ([]string, error) {
+	total, err := orderTotal(ctx, tx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	var paid float64
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(amount), 0) FROM payments WHERE order_id=$1 AND status IN ('paid','refunded')`, orderID).Scan(&paid); err != nil {
+		return nil, err
+	}
+	if total <= 0 || toCents(paid) < toCents(total) {
+		return nil, nil
+	}
+
+	var status string
+	if err := tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id=$1`, orderID).Scan(&status); err != nil {
+		return nil, err
+	}
+	path := map[string][]string{"new": {"in_progress", "closed"}, "in_progress": {"closed"}}[status]
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT auto_close`); err != nil {
+		return nil, err
+	}
+	var warnings []string
+	for _, next := range path {
+		w, err := r.transitionOrder(ctx, tx, orderID, next)
+		if errors.Is(err, ErrInsufficientStock) {
+			if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT auto_close`); rbErr != nil {
+				return nil, rbErr
+			}
+			return []string{fmt.Sprintf("order %d is paid but was not closed: %v", orderID, err)}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		warnings = append(warnings, w...)
+	}
+	_, err = tx.ExecContext(ctx, `RELEASE SAVEPOINT auto_close`)
+	return warnings, err
+}
+
+// amountDue is the order total minus payments that are paid, pending or were
+// refunded after settling, in cents.
+func amountDue(ctx context.Context, tx *sql.Tx, orderID int64) (int64, error) {
+	total, err := orderTotal(ctx, tx, orderID)
+	if err != nil {
+		return 0, err
+	}
+	var covered float64
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(amount), 0) FROM payments WHERE order_id=$1 AND status IN ('paid','pending','refunded')`, orderID).Scan(&covered)
+	return toCents(total) - toCents(covered), err
+}
+
+func insertPayment(ctx context.Context, tx *sql.Tx, p *domain.Payment) error {
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO payments(order_id, amount, method, status, paid_at)
+		VALUES ($1,$2,$3,$4,COALESCE($5, now()))
+		RETURNING id, paid_at`,
+		p.OrderID, p.Amount, p.Method, p.Status, sql.NullTime{Time: p.PaidAt, Valid: !p.PaidAt.IsZero()}).
+		Scan(&p.ID, &p.PaidAt)
+}
+
+// lockOrder locks the order for a payment change and refuses cancelled orders.
+func lockOrder(ctx context.Context, tx *sql.Tx, orderID int64) error {
+	var status string
+	if err := tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id=$1 FOR UPDATE`, orderID).Scan(&status); err != nil {
+		return err
+	}
+	if status == "cancelled" {
+		return fmt.Errorf("%w: order %d is cancelled", ErrOrderFinalized, orderID)
+	}
+	return nil
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func toCents(v float64) int64 {
+	return int64(math.Round(v * 100))
+}
+
+func fromCents(c int64) float64 {
+	return float64(c) / 100
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/example/rms/internal/domain"
+)
+
+// The even and custom modes do not touch the database, so they run without a tx.
+func TestSplitShares(t *testing.T) {
+	tests := []struct {
+		name        string
+		outstanding int64
+		split       domain.BillSplit
+		want        []int64
+		wantErr     error
+	}{
+		{
+			name:        "even split without remainder",
+			outstanding: 3000,
+			split:       domain.BillSplit{Mode: "even", Parts: 3, Method: "card"},
+			want:        []int64{1000, 1000, 1000},
+		},
+		{
+			name:        "first parts absorb the remainder",
+			outstanding: 1000,
+			split:       domain.BillSplit{Mode: "even", Parts: 3, Method: "card"},
+			want:        []int64{334, 333, 333},
+		},
+		{
+			name:        "remainder of two cents",
+			outstanding: 1001,
+			split:       domain.BillSplit{Mode: "even", Parts: 3, Method: "cash"},
+			want:        []int64{334, 334, 333},
+		},
+		{
+			name:        "even split needs two parts",
+			outstanding: 1000,
+			split:       domain.BillSplit{Mode: "even", Parts: 1, Method: "card"},
+			wantErr:     ErrInvalidSplit,
+		},
+		{
+			name:        "more parts than cents",
+			outstanding: 2,
+			split:       domain.BillSplit{Mode: "even", Parts: 3, Method: "card"},
+			wantErr:     ErrInvalidSplit,
+		},
+		{
+			name:        "even split needs a method",
+			outstanding: 1000,
+			split:       domain.BillSplit{Mode: "even", Parts: 2},
+			wantErr:     ErrInvalidSplit,
+		},
+		{
+			name:        "custom shares become cents",
+			outstanding: 1000,
+			split: domain.BillSplit{Mode: "custom", Shares: []domain.BillSplitShare{
+				{Amount: 3.34, Method: "cash"}, {Amount: 6.66, Method: "card"},
+			}},
+			want: []int64{334, 666},
+		},
+		{
+			name:        "custom shares may leave a balance",
+			outstanding: 1000,
+			split: domain.BillSplit{Mode: "custom", Shares: []domain.BillSplitShare{
+				{Amount: 4, Method: "cash"},
+			}},
+			want: []int64{400},
+		},
+		{
+			name:        "custom shares exceed the outstanding amount",
+			outstanding: 1000,
+			split: domain.BillSplit{Mode: "custom", Shares: []domain.BillSplitShare{
+				{Amount: 5, Method: "cash"}, {Amount: 5.01, Method: "card"},
+			}},
+			wantErr: ErrInvalidSplit,
+		},
+		{
+			name:        "custom share must be positive",
+			outstanding: 1000,
+			split: domain.BillSplit{Mode: "custom", Shares: []domain.BillSplitShare{
+				{Amount: 0, Method: "cash"},
+			}},
+			wantErr: ErrInvalidSplit,
+		},
+		{
+			name:        "unknown mode",
+			outstanding: 1000,
+			split:       domain.BillSplit{Mode: "random"},
+			wantErr:     ErrInvalidSplit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := splitShares(context.Background(), nil, 1, tt.outstanding, tt.split)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(shares) != len(tt.want) {
+				t.Fatalf("got %d shares, want %d", len(shares), len(tt.want))
+			}
+			for i, sh := range shares {
+				if sh.cents != tt.want[i] {
+					t.Errorf("share %d = %d cents, want %d", i, sh.cents, tt.want[i])
+				}
+			}
+		})
+	}
+}
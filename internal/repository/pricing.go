@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/example/rms/internal/domain"
+)
+
+// pricingLine is an order item as seen by the pricing engine.
+type pricingLine struct {
+	itemID          int64
+	price           float64
+	quantity        int
+	discountPercent float64
+}
+
+// billLines holds an order's lines and rates, loaded once for pricing.
+type billLines struct {
+	lines                  []pricingLine
+	discountPercent        float64
+	loyaltyDiscountPercent float64
+	serviceChargePercent   float64
+}
+
+func loadBillLines(ctx context.Context, q queryer, orderID int64) (billLines, error) {
+	var b billLines
+	err := q.QueryRowContext(ctx, `SELECT discount_percent, loyalty_discount_percent, service_charge_percent FROM orders WHERE id=$1`, orderID).
+		Scan(&b.discountPercent, &b.loyaltyDiscountPercent, &b.serviceChargePercent)
+	if err != nil {
+		return b, err
+	}
+	rows, err := q.QueryContext(ctx, `SELECT id, price_at_moment, quantity, discount_percent FROM order_items WHERE order_id=$1 ORDER BY id`, orderID)
+	if err != nil {
+		return b, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var l pricingLine
+		if err := rows.Scan(&l.itemID, &l.price, &l.quantity, &l.discountPercent); err != nil {
+			return b, err
+		}
+		b.lines = append(b.lines, l)
+	}
+	return b, rows.Err()
+}
+
+// price computes the bill in cents: line discounts first, then the order
+// discount on what is left, then the loyalty discount, then the service charge
+// on the discounted amount.
+// It also returns each line's amount after its own discount.
+func (b billLines) price() (domain.OrderPricing, map[int64]int64) {
+	var subtotal, lineDiscounts int64
+	net := make(map[int64]int64, len(b.lines))
+	for _, l := range b.lines {
+		gross := toCents(l.price) * int64(l.quantity)
+		discount := percentOf(gross, l.discountPercent)
+		subtotal += gross
+		lineDiscounts += discount
+		net[l.itemID] = gross - discount
+	}
+	afterLines := subtotal - lineDiscounts
+	orderDiscount := percentOf(afterLines, b.discountPercent)
+	loyaltyDiscount := percentOf(afterLines-orderDiscount, b.loyaltyDiscountPercent)
+	discounted := afterLines - orderDiscount - loyaltyDiscount
+	serviceCharge := percentOf(discounted, b.serviceChargePercent)
+
+	return domain.OrderPricing{
+		Subtotal:               fromCents(subtotal),
+		LineDiscounts:          fromCents(lineDiscounts),
+		OrderDiscountPercent:   b.discountPercent,
+		OrderDiscount:          fromCents(orderDiscount),
+		LoyaltyDiscountPercent: b.loyaltyDiscountPercent,
+		LoyaltyDiscount:        fromCents(loyaltyDiscount),
+		ServiceChargePercent:   b.serviceChargePercent,
+		ServiceCharge:          fromCents(serviceCharge),
+		Total:                  fromCents(discounted + serviceCharge),
+	}, net
+}
+
+// priceOrder returns the bill breakdown for the order.
+func priceOrder(ctx context.Context, q queryer, orderID int64) (domain.OrderPricing, error) {
+	b, err := loadBillLines(ctx, q, orderID)
+	if err != nil {
+		return domain.OrderPricing{}, err
+	}
+	p, _ := b.price()
+	return p, nil
+}
+
+// orderTotal is the grand total of the order after discounts and service charge.
+func orderTotal(ctx context.Context, q queryer, orderID int64) (float64, error) {
+	p, err := priceOrder(ctx, q, orderID)
+	return p.Total, err
+}
+
+// orderItemAmounts returns what each order item contributes to the grand total,
+// in cents keyed by order item id: its discounted line amount with the order
+// discount and service charge spread proportionally.
+func orderItemAmounts(ctx context.Context, q queryer, orderID int64) (map[int64]int64, error) {
+	b, err := loadBillLines(ctx, q, orderID)
+	if err != nil {
+		return nil, err
+	}
+	p, net := b.price()
+	var afterLines int64
+	for _, cents := range net {
+		afterLines += cents
+	}
+	res := make(map[int64]int64, len(net))
+	for id, cents := range net {
+		if afterLines == 0 {
+			res[id] = 0
+			continue
+		}
+		res[id] = int64(math.Round(float64(cents) * float64(toCents(p.Total)) / float64(afterLines)))
+	}
+	return res, nil
+}
+
+// SetOrderPricing changes the order discount and, optionally, its service
+// charge rate. Both need the acting employee to hold orders:discount and are refused once the order is settled.
+// An order whose payments now cover the lower total is closed, as after a
+// payment; the warnings are those of closing it.
+func (r *Repository) SetOrderPricing(ctx context.Context, orderID int64, discountPercent float64, serviceChargePercent *float64) (domain.OrderPricing, []string, error) {
+	var p domain.OrderPricing
+	var warnings []string
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		var status string
+		if err := tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id=$1 FOR UPDATE`, orderID).Scan(&status); err != nil {
+			return err
+		}
+		if status == "closed" || status == "cancelled" {
+			return fmt.Errorf("%w: order %d is %s", ErrOrderFinalized, orderID, status)
+		}
+		approvedBy, ok := EmployeeFromContext(ctx)
+		if !ok {
+			return fmt.Errorf("%w: no authenticated employee", ErrOverrideForbidden)
+		}
+		ok, err := employeeCan(ctx, tx, approvedBy, "orders:discount")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: employee %d cannot change order pricing", ErrOverrideForbidden, approvedBy)
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE orders SET discount_percent=$1, service_charge_percent=COALESCE($2, service_charge_percent)
+			WHERE id=$3`, discountPercent, serviceChargePercent, orderID)
+		if err != nil {
+			return err
+		}
+		if warnings, err = r.closeOrderIfPaid(ctx, tx, orderID); err != nil {
+			return err
+		}
+		p, err = priceOrder(ctx, tx, orderID)
+		return err
+	})
+	return p, warnings, err
+}
+
+func percentOf(cents int64, percent float64) int64 {
+	return int64(math.Round(float64(cents) * percent / 100))
+}
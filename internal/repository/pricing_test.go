@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/example/rms/internal/domain"
+)
+
+func TestBillLinesPrice(t *testing.T) {
+	tests := []struct {
+		name string
+		bill billLines
+		want domain.OrderPricing
+		net  map[int64]int64
+	}{
+		{
+			name: "empty order",
+			want: domain.OrderPricing{},
+			net:  map[int64]int64{},
+		},
+		{
+			name: "no discounts",
+			bill: billLines{lines: []pricingLine{{itemID: 1, price: 10, quantity: 2}}},
+			want: domain.OrderPricing{Subtotal: 20, Total: 20},
+			net:  map[int64]int64{1: 2000},
+		},
+		{
+			name: "line, order and loyalty discounts before service charge",
+			bill: billLines{
+				lines: []pricingLine{
+					{itemID: 1, price: 12.5, quantity: 2, discountPercent: 10},
+					{itemID: 2, price: 5, quantity: 1},
+				},
+				discountPercent:        10,
+				loyaltyDiscountPercent: 5,
+				serviceChargePercent:   10,
+			},
+			// 27.50 after lines, 2.75 order discount, 5% of 24.75 loyalty,
+			// 10% of 23.51 service.
+			want: domain.OrderPricing{
+				Subtotal:               30,
+				LineDiscounts:          2.5,
+				OrderDiscountPercent:   10,
+				OrderDiscount:          2.75,
+				LoyaltyDiscountPercent: 5,
+				LoyaltyDiscount:        1.24,
+				ServiceChargePercent:   10,
+				ServiceCharge:          2.35,
+				Total:                  25.86,
+			},
+			net: map[int64]int64{1: 2250, 2: 500},
+		},
+		{
+			name: "each step rounds to the cent",
+			bill: billLines{
+				lines:                []pricingLine{{itemID: 3, price: 0.33, quantity: 3}},
+				discountPercent:      50,
+				serviceChargePercent: 12.5,
+			},
+			want: domain.OrderPricing{
+				Subtotal:             0.99,
+				OrderDiscountPercent: 50,
+				OrderDiscount:        0.5,
+				ServiceChargePercent: 12.5,
+				ServiceCharge:        0.06,
+				Total:                0.55,
+			},
+			net: map[int64]int64{3: 99},
+		},
+		{
+			name: "full line discount",
+			bill: billLines{
+				lines:                []pricingLine{{itemID: 4, price: 7, quantity: 1, discountPercent: 100}},
+				serviceChargePercent: 10,
+			},
+			want: domain.OrderPricing{Subtotal: 7, LineDiscounts: 7, ServiceChargePercent: 10},
+			net:  map[int64]int64{4: 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, net := tt.bill.price()
+			if got != tt.want {
+				t.Errorf("price() = %+v, want %+v", got, tt.want)
+			}
+			if len(net) != len(tt.net) {
+				t.Fatalf("net = %v, want %v", net, tt.net)
+			}
+			for id, cents := range tt.net {
+				if net[id] != cents {
+					t.Errorf("net[%d] = %d, want %d", id, net[id], cents)
+				}
+			}
+		})
+	}
+}
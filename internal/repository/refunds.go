@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/example/rms/internal/domain"
+)
+
+// ListRefunds returns the refunds recorded against a payment, oldest first.
+func (r *Repository) ListRefunds(ctx context.Context, paymentID int64) ([]domain.Refund, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT rf.id, rf.payment_id, p.order_id, rf.amount, rf.reason, rf.approved_by, rf.created_at
+		FROM refunds rf
+		JOIN payments p ON p.id = rf.payment_id
+		WHERE rf.payment_id=$1
+		ORDER BY rf.id`, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Refund
+	for rows.Next() {
+		var rf domain.Refund
+		if err := rows.Scan(&rf.ID, &rf.PaymentID, &rf.OrderID, &rf.Amount, &rf.Reason, &rf.ApprovedBy, &rf.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, rf)
+	}
+	return res, rows.Err()
+}
+
+// RefundPayment records a partial or full refund of a paid payment. A zero
+// amount refunds whatever is left; the payment turns 'refunded' once nothing is.
+// The acting employee approves the refund and must hold payments:refund.
+func (r *Repository) RefundPayment(ctx context.Context, rf *domain.Refund) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		var amount, refunded float64
+		var status string
+		err := tx.QueryRowContext(ctx, `SELECT order_id, amount, status FROM payments WHERE id=$1 FOR UPDATE`, rf.PaymentID).
+			Scan(&rf.OrderID, &amount, &status)
+		if err != nil {
+			return err
+		}
+		if status != "paid" {
+			return fmt.Errorf("%w: payment %d is %s", ErrInvalidRefund, rf.PaymentID, status)
+		}
+		if rf.Reason == "" {
+			return fmt.Errorf("%w: reason is required", ErrInvalidRefund)
+		}
+		approver, ok := EmployeeFromContext(ctx)
+		if !ok {
+			return fmt.Errorf("%w: no authenticated employee", ErrRefundForbidden)
+		}
+		rf.ApprovedBy = approver
+		ok, err = employeeCan(ctx, tx, rf.ApprovedBy, "payments:refund")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: employee %d cannot approve refunds", ErrRefundForbidden, rf.ApprovedBy)
+		}
+
+		if err := tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(amount), 0) FROM refunds WHERE payment_id=$1`, rf.PaymentID).Scan(&refunded); err != nil {
+			return err
+		}
+		remaining := toCents(amount) - toCents(refunded)
+		if rf.Amount == 0 {
+			rf.Amount = fromCents(remaining)
+		}
+		if toCents(rf.Amount) <= 0 || toCents(rf.Amount) > remaining {
+			return fmt.Errorf("%w: amount must be between 0.01 and the %.2f left on payment %d", ErrInvalidRefund, fromCents(remaining), rf.PaymentID)
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO refunds(payment_id, amount, reason, approved_by)
+			VALUES ($1,$2,$3,$4)
+			RETURNING id, created_at`, rf.PaymentID, rf.Amount, rf.Reason, rf.ApprovedBy).
+			Scan(&rf.ID, &rf.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if toCents(rf.Amount) == remaining {
+			if _, err := tx.ExecContext(ctx, `UPDATE payments SET status='refunded' WHERE id=$1`, rf.PaymentID); err != nil {
+				return err
+			}
+		}
+		return r.refreshCustomerLevel(ctx, tx, rf.OrderID)
+	})
+}
+
+// orderRefunded returns the total refunded on the order's payments.
+func orderRefunded(ctx context.Context, q queryer, orderID int64) (float64, error) {
+	var total float64
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(rf.amount), 0)
+		FROM refunds rf
+		JOIN payments p ON p.id = rf.payment_id
+		WHERE p.order_id=$1`, orderID).Scan(&total)
+	return total, err
+}
@@ -3,20 +3,35 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/example/rms/internal/config"
 	"github.com/example/rms/internal/domain"
 )
 
 // Repository contains all DB interactions in one place for simplicity.
 type Repository struct {
-	DB *sql.DB
+	DB  *sql.DB
+	Cfg *config.Config
 }
 
-func New(db *sql.DB) *Repository {
-	return &Repository{DB: db}
+func New(db *sql.DB, cfg *config.Config) *Repository {
+	return &Repository{DB: db, Cfg: cfg}
+}
+
+// inTx runs fn in a transaction, committing when fn succeeds and rolling back otherwise.
+func (r *Repository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
 
 // Common helper
@@ -28,81 +43,83 @@ func scanNullableString(s sql.NullString) *string {
 }
 
 // Customers
-func (r *Repository) ListCustomers(ctx context.Context) ([]domain.Customer, error) {
-	rows, err := r.DB.QueryContext(ctx, `SELECT id, full_name, phone, email, created_at, vip_level FROM customers ORDER BY created_at DESC LIMIT 200`)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var res []domain.Customer
-	for rows.Next() {
-		var c domain.Customer
-		var email sql.NullString
-		if err := rows.Scan(&c.ID, &c.FullName, &c.Phone, &email, &c.CreatedAt, &c.VIPLevel); err != nil {
-			return nil, err
-		}
-		c.Email = scanNullableString(email)
-		res = append(res, c)
-	}
-	return res, rows.Err()
-}
-
 func (r *Repository) CreateCustomer(ctx context.Context, c *domain.Customer) error {
-	return r.DB.QueryRowContext(ctx, `
-		INSERT INTO customers (full_name, phone, email, vip_level)
-		VALUES ($1,$2,$3,$4) RETURNING id, created_at`,
-		c.FullName, c.Phone, c.Email, c.VIPLevel).Scan(&c.ID, &c.CreatedAt)
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return insertCustomer(ctx, tx, c, false)
+	})
+}
+
+// insertCustomer stores a new customer. With keepID the customer's id,
+// created_at and VIP level are kept, which is how a deleted customer is
+// restored; otherwise the customer starts at level 0, as the loyalty engine
+// owns vip_level.
+func insertCustomer(ctx context.Context, tx *sql.Tx, c *domain.Customer, keepID bool) error {
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO customers (id, full_name, phone, email, vip_level, created_at)
+		VALUES (COALESCE($1, nextval(pg_get_serial_sequence('customers', 'id'))),$2,$3,$4,COALESCE($5, 0),COALESCE($6::timestamp, now()))
+		RETURNING id, created_at, vip_level`,
+		sql.NullInt64{Int64: c.ID, Valid: keepID}, c.FullName, c.Phone, c.Email,
+		sql.NullInt64{Int64: int64(c.VIPLevel), Valid: keepID}, sql.NullTime{Time: c.CreatedAt, Valid: keepID}).
+		Scan(&c.ID, &c.CreatedAt, &c.VIPLevel)
+}
+
+// UpdateCustomer changes the customer's contact details; vip_level is left
+// to the loyalty engine.
+func (r *Repository) UpdateCustomer(ctx context.Context, id int64, c *domain.Customer) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return updateCustomer(ctx, tx, id, c)
+	})
 }
 
-func (r *Repository) UpdateCustomer(ctx context.Context, id int64, c *domain.Customer) error {
-	res, err := r.DB.ExecContext(ctx, `
-		UPDATE customers SET full_name=$1, phone=$2, email=$3, vip_level=$4 WHERE id=$5`,
-		c.FullName, c.Phone, c.Email, c.VIPLevel, id)
-	if err != nil {
-		return err
-	}
-	aff, _ := res.RowsAffected()
-	if aff == 0 {
-		return sql.ErrNoRows
-	}
-	return nil
+func updateCustomer(ctx context.Context, tx *sql.Tx, id int64, c *domain.Customer) error {
+	return tx.QueryRowContext(ctx, `
+		UPDATE customers SET full_name=$1, phone=$2, email=$3 WHERE id=$4
+		RETURNING created_at, vip_level`,
+		c.FullName, c.Phone, c.Email, id).
+		Scan(&c.CreatedAt, &c.VIPLevel)
 }
 
 func (r *Repository) DeleteCustomer(ctx context.Context, id int64) error {
-	_, err := r.DB.ExecContext(ctx, `DELETE FROM customers WHERE id=$1`, id)
+	_, err := r.exec(ctx, `DELETE FROM customers WHERE id=$1`, id)
 	return err
 }
 
 // Employees
-func (r *Repository) ListEmployees(ctx context.Context) ([]domain.Employee, error) {
-	rows, err := r.DB.QueryContext(ctx, `SELECT id, full_name, phone, email, role_id, hired_at, is_active FROM employees ORDER BY id`)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var res []domain.Employee
-	for rows.Next() {
+var employeeList = listSpec{
+	table:   "employees",
+	columns: "id, full_name, phone, email, role_id, hired_at, is_active",
+	fields: map[string]listField{
+		"id":        {column: "id", kind: "bigint", sortable: true},
+		"full_name": {column: "full_name", kind: "text", sortable: true},
+		"phone":     {column: "phone", kind: "text"},
+		"role_id":   {column: "role_id", kind: "bigint"},
+		"hired_at":  {column: "hired_at", kind: "date", sortable: true},
+		"is_active": {column: "is_active", kind: "boolean"},
+	},
+	defaultSort: "id",
+}
+
+func (r *Repository) ListEmployees(ctx context.Context, q ListQuery) (domain.Page, error) {
+	return listPage(ctx, r.DB, employeeList, q, func(row rowScanner) (domain.Employee, error) {
 		var e domain.Employee
 		var email sql.NullString
-		if err := rows.Scan(&e.ID, &e.FullName, &e.Phone, &email, &e.RoleID, &e.HiredAt, &e.IsActive); err != nil {
-			return nil, err
+		if err := row.Scan(&e.ID, &e.FullName, &e.Phone, &email, &e.RoleID, &e.HiredAt, &e.IsActive); err != nil {
+			return e, err
 		}
 		e.Email = scanNullableString(email)
-		res = append(res, e)
-	}
-	return res, rows.Err()
+		return e, nil
+	})
 }
 
 func (r *Repository) CreateEmployee(ctx context.Context, e *domain.Employee) error {
-	return r.DB.QueryRowContext(ctx, `
+	return r.execReturning(ctx, `
 		INSERT INTO employees(full_name, phone, email, role_id, hired_at, is_active)
 		VALUES ($1,$2,$3,$4,$5,$6) RETURNING id`,
-		e.FullName, e.Phone, e.Email, e.RoleID, e.HiredAt, e.IsActive).Scan(&e.ID)
+		[]interface{}{e.FullName, e.Phone, e.Email, e.RoleID, e.HiredAt, e.IsActive}, &e.ID)
 }
 
 func (r *Repository) UpdateEmployee(ctx context.Context, id int64, e *domain.Employee) error {
-	res, err := r.DB.ExecContext(ctx, `
+	res, err := r.exec(ctx, `
 		UPDATE employees SET full_name=$1, phone=$2, email=$3, role_id=$4, is_active=$5 WHERE id=$6`,
 		e.FullName, e.Phone, e.Email, e.RoleID, e.IsActive, id)
 	if err != nil {
@@ -116,7 +133,7 @@ func (r *Repository) UpdateEmployee(ctx context.Context, id int64, e *domain.Emp
 }
 
 func (r *Repository) DeleteEmployee(ctx context.Context, id int64) error {
-	_, err := r.DB.ExecContext(ctx, `DELETE FROM employees WHERE id=$1`, id)
+	_, err := r.exec(ctx, `DELETE FROM employees WHERE id=$1`, id)
 	return err
 }
 
@@ -138,16 +155,45 @@ func (r *Repository) ListTables(ctx context.Context) ([]domain.RestaurantTable,
 	return res, rows.Err()
 }
 
+// ListAvailableTables returns active tables with enough seats and no live reservation
+// overlapping [from, to), smallest sufficient tables first.
+func (r *Repository) ListAvailableTables(ctx context.Context, from, to time.Time, guests int) ([]domain.RestaurantTable, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT t.id, t.table_number, t.seats, t.is_active, COALESCE(t.description,'')
+		FROM restaurant_tables t
+		WHERE t.is_active AND t.seats >= $3
+		  AND NOT EXISTS (
+			SELECT 1 FROM reservations rsv
+			WHERE rsv.table_id = t.id
+			  AND rsv.status NOT IN ('cancelled','no_show')
+			  AND rsv.reserved_range && tsrange($1::timestamp, $2::timestamp, '[)')
+		  )
+		ORDER BY t.seats - $3, t.table_number`, from, to, guests)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.RestaurantTable
+	for rows.Next() {
+		var t domain.RestaurantTable
+		if err := rows.Scan(&t.ID, &t.TableNumber, &t.Seats, &t.IsActive, &t.Description); err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
 func (r *Repository) UpsertTable(ctx context.Context, t *domain.RestaurantTable) error {
-	return r.DB.QueryRowContext(ctx, `
+	return r.execReturning(ctx, `
 		INSERT INTO restaurant_tables(table_number, seats, is_active, description)
 		VALUES ($1,$2,$3,$4)
 		ON CONFLICT (table_number) DO UPDATE SET seats=EXCLUDED.seats, is_active=EXCLUDED.is_active, description=EXCLUDED.description
-		RETURNING id`, t.TableNumber, t.Seats, t.IsActive, t.Description).Scan(&t.ID)
+		RETURNING id`, []interface{}{t.TableNumber, t.Seats, t.IsActive, t.Description}, &t.ID)
 }
 
 func (r *Repository) DeleteTable(ctx context.Context, id int64) error {
-	_, err := r.DB.ExecContext(ctx, `DELETE FROM restaurant_tables WHERE id=$1`, id)
+	_, err := r.exec(ctx, `DELETE FROM restaurant_tables WHERE id=$1`, id)
 	return err
 }
 
@@ -174,233 +220,568 @@ func (r *Repository) ListMenuCategories(ctx context.Context) ([]domain.MenuCateg
 }
 
 func (r *Repository) UpsertMenuCategory(ctx context.Context, c *domain.MenuCategory) error {
-	return r.DB.QueryRowContext(ctx, `
+	return r.execReturning(ctx, `
 		INSERT INTO menu_categories(name, description, sort_order, is_active)
 		VALUES ($1,$2,$3,$4)
 		ON CONFLICT (name) DO UPDATE SET description=EXCLUDED.description, sort_order=EXCLUDED.sort_order, is_active=EXCLUDED.is_active
-		RETURNING id`, c.Name, c.Description, c.SortOrder, c.IsActive).Scan(&c.ID)
+		RETURNING id`, []interface{}{c.Name, c.Description, c.SortOrder, c.IsActive}, &c.ID)
 }
 
 func (r *Repository) DeleteMenuCategory(ctx context.Context, id int64) error {
-	_, err := r.DB.ExecContext(ctx, `DELETE FROM menu_categories WHERE id=$1`, id)
+	_, err := r.exec(ctx, `DELETE FROM menu_categories WHERE id=$1`, id)
 	return err
 }
 
 // Products
-func (r *Repository) ListProducts(ctx context.Context, limit int) ([]domain.Product, error) {
-	if limit <= 0 || limit > 500 {
-		limit = 200
-	}
-	rows, err := r.DB.QueryContext(ctx, `SELECT id, name, unit, cost_price, is_available FROM products ORDER BY id LIMIT $1`, limit)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var res []domain.Product
-	for rows.Next() {
+var productList = listSpec{
+	table:   "products",
+	columns: "id, name, unit, cost_price, is_available",
+	fields: map[string]listField{
+		"id":           {column: "id", kind: "bigint", sortable: true},
+		"name":         {column: "name", kind: "text", sortable: true},
+		"unit":         {column: "unit", kind: "text"},
+		"cost_price":   {column: "cost_price", kind: "numeric"},
+		"is_available": {column: "is_available", kind: "boolean"},
+	},
+	defaultSort: "id",
+}
+
+func (r *Repository) ListProducts(ctx context.Context, q ListQuery) (domain.Page, error) {
+	return listPage(ctx, r.DB, productList, q, func(row rowScanner) (domain.Product, error) {
 		var p domain.Product
 		var cp sql.NullFloat64
-		if err := rows.Scan(&p.ID, &p.Name, &p.Unit, &cp, &p.IsAvailable); err != nil {
-			return nil, err
+		if err := row.Scan(&p.ID, &p.Name, &p.Unit, &cp, &p.IsAvailable); err != nil {
+			return p, err
 		}
 		if cp.Valid {
 			val := cp.Float64
 			p.CostPrice = &val
 		}
-		res = append(res, p)
-	}
-	return res, rows.Err()
+		return p, nil
+	})
 }
 
 func (r *Repository) UpsertProduct(ctx context.Context, p *domain.Product) error {
-	return r.DB.QueryRowContext(ctx, `
-		INSERT INTO products(name, unit, cost_price, is_available)
-		VALUES ($1,$2,$3,$4)
-		ON CONFLICT (name) DO UPDATE SET unit=EXCLUDED.unit, cost_price=EXCLUDED.cost_price, is_available=EXCLUDED.is_available
-		RETURNING id`, p.Name, p.Unit, p.CostPrice, p.IsAvailable).Scan(&p.ID)
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return saveProduct(ctx, tx, p, false)
+	})
+}
+
+// saveProduct inserts or updates a product matched by name, or by id with
+// keepID, which is how a product is restored. Once a product has a stock row
+// its availability follows the stock, so the given IsAvailable only applies
+// to products without one.
+func saveProduct(ctx context.Context, tx *sql.Tx, p *domain.Product, keepID bool) error {
+	if p.Name == "" || p.Unit == "" {
+		return fmt.Errorf("%w: name and unit are required", ErrInvalidRecord)
+	}
+	key := "name"
+	if keepID {
+		key = "id"
+	}
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO products(id, name, unit, cost_price, is_available)
+		VALUES (COALESCE($1, nextval(pg_get_serial_sequence('products', 'id'))),$2,$3,$4,$5)
+		ON CONFLICT (`+key+`) DO UPDATE SET name=EXCLUDED.name, unit=EXCLUDED.unit, cost_price=EXCLUDED.cost_price,
+			is_available=COALESCE((SELECT s.quantity > 0 FROM product_stock s WHERE s.product_id = products.id), EXCLUDED.is_available)
+		RETURNING id, is_available`,
+		sql.NullInt64{Int64: p.ID, Valid: keepID}, p.Name, p.Unit, p.CostPrice, p.IsAvailable).
+		Scan(&p.ID, &p.IsAvailable)
 }
 
 func (r *Repository) DeleteProduct(ctx context.Context, id int64) error {
-	_, err := r.DB.ExecContext(ctx, `DELETE FROM products WHERE id=$1`, id)
+	_, err := r.exec(ctx, `DELETE FROM products WHERE id=$1`, id)
 	return err
 }
 
 // Dishes
-func (r *Repository) ListDishes(ctx context.Context, limit int) ([]domain.Dish, error) {
-	if limit <= 0 || limit > 500 {
-		limit = 200
-	}
-	rows, err := r.DB.QueryContext(ctx, `SELECT id, category_id, name, price, cook_time_minutes, is_active, COALESCE(description,'') FROM dishes ORDER BY id LIMIT $1`, limit)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var res []domain.Dish
-	for rows.Next() {
+var dishList = listSpec{
+	table:   "dishes",
+	columns: "id, category_id, name, price, cook_time_minutes, is_active, COALESCE(description,'')",
+	fields: map[string]listField{
+		"id":                {column: "id", kind: "bigint", sortable: true},
+		"category_id":       {column: "category_id", kind: "bigint"},
+		"name":              {column: "name", kind: "text", sortable: true},
+		"price":             {column: "price", kind: "numeric", sortable: true},
+		"cook_time_minutes": {column: "cook_time_minutes", kind: "int"},
+		"is_active":         {column: "is_active", kind: "boolean"},
+	},
+	defaultSort: "id",
+}
+
+func (r *Repository) ListDishes(ctx context.Context, q ListQuery) (domain.Page, error) {
+	return listPage(ctx, r.DB, dishList, q, func(row rowScanner) (domain.Dish, error) {
 		var d domain.Dish
-		if err := rows.Scan(&d.ID, &d.CategoryID, &d.Name, &d.Price, &d.CookTimeMinutes, &d.IsActive, &d.Description); err != nil {
-			return nil, err
-		}
-		res = append(res, d)
-	}
-	return res, rows.Err()
+		err := row.Scan(&d.ID, &d.CategoryID, &d.Name, &d.Price, &d.CookTimeMinutes, &d.IsActive, &d.Description)
+		return d, err
+	})
 }
 
 func (r *Repository) UpsertDish(ctx context.Context, d *domain.Dish) error {
-	return r.DB.QueryRowContext(ctx, `
-		INSERT INTO dishes(category_id, name, price, cook_time_minutes, is_active, description)
-		VALUES ($1,$2,$3,$4,$5,$6)
-		ON CONFLICT (category_id, name) DO UPDATE SET price=EXCLUDED.price, cook_time_minutes=EXCLUDED.cook_time_minutes, is_active=EXCLUDED.is_active, description=EXCLUDED.description
-		RETURNING id`, d.CategoryID, d.Name, d.Price, d.CookTimeMinutes, d.IsActive, d.Description).Scan(&d.ID)
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return saveDish(ctx, tx, d, false)
+	})
+}
+
+// saveDish inserts or updates a dish matched by category and name, or by id
+// with keepID, which is how a dish is restored.
+func saveDish(ctx context.Context, tx *sql.Tx, d *domain.Dish, keepID bool) error {
+	if d.Name == "" || d.CategoryID == 0 {
+		return fmt.Errorf("%w: name and category_id are required", ErrInvalidRecord)
+	}
+	key := "category_id, name"
+	if keepID {
+		key = "id"
+	}
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO dishes(id, category_id, name, price, cook_time_minutes, is_active, description)
+		VALUES (COALESCE($1, nextval(pg_get_serial_sequence('dishes', 'id'))),$2,$3,$4,$5,$6,NULLIF($7,''))
+		ON CONFLICT (`+key+`) DO UPDATE SET category_id=EXCLUDED.category_id, name=EXCLUDED.name, price=EXCLUDED.price,
+			cook_time_minutes=EXCLUDED.cook_time_minutes, is_active=EXCLUDED.is_active, description=EXCLUDED.description
+		RETURNING id`,
+		sql.NullInt64{Int64: d.ID, Valid: keepID}, d.CategoryID, d.Name, d.Price, d.CookTimeMinutes, d.IsActive, d.Description).
+		Scan(&d.ID)
 }
 
 func (r *Repository) DeleteDish(ctx context.Context, id int64) error {
-	_, err := r.DB.ExecContext(ctx, `DELETE FROM dishes WHERE id=$1`, id)
+	_, err := r.exec(ctx, `DELETE FROM dishes WHERE id=$1`, id)
 	return err
 }
 
 // Reservations
-func (r *Repository) ListReservations(ctx context.Context, status string) ([]domain.Reservation, error) {
-	query := `SELECT id, customer_id, table_id, reserved_from, reserved_to, status, created_at FROM reservations`
-	args := []interface{}{}
-	if status != "" {
-		query += ` WHERE status = $1`
-		args = append(args, status)
-	}
-	query += ` ORDER BY reserved_from DESC LIMIT 200`
-
-	rows, err := r.DB.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var res []domain.Reservation
-	for rows.Next() {
+var reservationList = listSpec{
+	table:   "reservations",
+	columns: "id, customer_id, table_id, reserved_from, reserved_to, status, created_at",
+	fields: map[string]listField{
+		"id":            {column: "id", kind: "bigint", sortable: true},
+		"customer_id":   {column: "customer_id", kind: "bigint"},
+		"table_id":      {column: "table_id", kind: "bigint"},
+		"status":        {column: "status", kind: "text"},
+		"reserved_from": {column: "reserved_from", kind: "timestamp", sortable: true},
+		"reserved_to":   {column: "reserved_to", kind: "timestamp", sortable: true},
+		"created_at":    {column: "created_at", kind: "timestamp", sortable: true},
+	},
+	defaultSort: "-reserved_from",
+}
+
+func (r *Repository) ListReservations(ctx context.Context, q ListQuery) (domain.Page, error) {
+	return listPage(ctx, r.DB, reservationList, q, func(row rowScanner) (domain.Reservation, error) {
 		var rsv domain.Reservation
-        if err := rows.Scan(&rsv.ID, &rsv.CustomerID, &rsv.TableID, &rsv.ReservedFrom, &rsv.ReservedTo, &rsv.Status, &rsv.CreatedAt); err != nil {
-			return nil, err
-		}
-		res = append(res, rsv)
-	}
-	return res, rows.Err()
+		err := row.Scan(&rsv.ID, &rsv.CustomerID, &rsv.TableID, &rsv.ReservedFrom, &rsv.ReservedTo, &rsv.Status, &rsv.CreatedAt)
+		return rsv, err
+	})
 }
 
+// CreateReservation stores the reservation. When reservations_no_overlap rejects it,
+// a *ReservationConflictError describing the blocking reservation is returned.
 func (r *Repository) CreateReservation(ctx context.Context, rsv *domain.Reservation) error {
-	return r.DB.QueryRowContext(ctx, `
-		INSERT INTO reservations(customer_id, table_id, reserved_from, reserved_to, status)
-		VALUES ($1,$2,$3,$4,$5) RETURNING id, created_at`,
-		rsv.CustomerID, rsv.TableID, rsv.ReservedFrom, rsv.ReservedTo, rsv.Status).
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return insertReservation(ctx, tx, rsv, false)
+	})
+}
+
+// insertReservation is CreateReservation inside tx. With keepID the
+// reservation's id and created_at are kept, which is how a deleted reservation
+// is restored.
+func insertReservation(ctx context.Context, tx *sql.Tx, rsv *domain.Reservation, keepID bool) error {
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT insert_reservation`); err != nil {
+		return err
+	}
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO reservations(id, customer_id, table_id, reserved_from, reserved_to, status, created_at)
+		VALUES (COALESCE($1, nextval(pg_get_serial_sequence('reservations', 'id'))),$2,$3,$4,$5,$6,COALESCE($7::timestamp, now()))
+		RETURNING id, created_at`,
+		sql.NullInt64{Int64: rsv.ID, Valid: keepID}, rsv.CustomerID, rsv.TableID, rsv.ReservedFrom, rsv.ReservedTo, rsv.Status,
+		sql.NullTime{Time: rsv.CreatedAt, Valid: keepID}).
 		Scan(&rsv.ID, &rsv.CreatedAt)
+	if !isExclusionViolation(err, "reservations_no_overlap") {
+		return err
+	}
+	if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT insert_reservation`); rbErr != nil {
+		return rbErr
+	}
+
+	var conflict domain.Reservation
+	lookupErr := tx.QueryRowContext(ctx, `
+		SELECT id, customer_id, table_id, reserved_from, reserved_to, status, created_at
+		FROM reservations
+		WHERE table_id=$1
+		  AND status NOT IN ('cancelled','no_show')
+		  AND reserved_range && tsrange($2::timestamp, $3::timestamp, '[)')
+		ORDER BY reserved_from
+		LIMIT 1`, rsv.TableID, rsv.ReservedFrom, rsv.ReservedTo).
+		Scan(&conflict.ID, &conflict.CustomerID, &conflict.TableID, &conflict.ReservedFrom, &conflict.ReservedTo, &conflict.Status, &conflict.CreatedAt)
+	if lookupErr != nil {
+		return err
+	}
+	return &ReservationConflictError{Conflicting: conflict}
 }
 
+// UpdateReservationStatus moves the reservation along the reservation status machine.
 func (r *Repository) UpdateReservationStatus(ctx context.Context, id int64, status string) error {
-	_, err := r.DB.ExecContext(ctx, `UPDATE reservations SET status=$1 WHERE id=$2`, status, id)
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return transitionReservation(ctx, tx, id, status)
+	})
+}
+
+func transitionReservation(ctx context.Context, tx *sql.Tx, id int64, status string) error {
+	var current string
+	if err := tx.QueryRowContext(ctx, `SELECT status FROM reservations WHERE id=$1 FOR UPDATE`, id).Scan(&current); err != nil {
+		return err
+	}
+	if err := reservationStatuses.check(current, status); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `UPDATE reservations SET status=$1 WHERE id=$2`, status, id)
 	return err
 }
 
+// MarkNoShowReservations marks confirmed reservations that started more than grace ago
+// and have no order as no_show, which releases their table slot.
+func (r *Repository) MarkNoShowReservations(ctx context.Context, grace time.Duration) ([]int64, error) {
+	var ids []int64
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			UPDATE reservations rsv SET status='no_show'
+			WHERE rsv.status='confirmed'
+			  AND rsv.reserved_from < now() - $1 * interval '1 second'
+			  AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.reservation_id = rsv.id)
+			RETURNING rsv.id`, grace.Seconds())
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		return rows.Err()
+	})
+	return ids, err
+}
+
 func (r *Repository) DeleteReservation(ctx context.Context, id int64) error {
-	_, err := r.DB.ExecContext(ctx, `DELETE FROM reservations WHERE id=$1`, id)
+	_, err := r.exec(ctx, `DELETE FROM reservations WHERE id=$1`, id)
 	return err
 }
 
 // Orders
-func (r *Repository) ListOrders(ctx context.Context, status string, limit int) ([]domain.Order, error) {
-	if limit <= 0 || limit > 300 {
-		limit = 100
+var orderList = listSpec{
+	table:   "orders",
+	columns: orderColumns,
+	fields: map[string]listField{
+		"id":             {column: "id", kind: "bigint", sortable: true},
+		"table_id":       {column: "table_id", kind: "bigint"},
+		"customer_id":    {column: "customer_id", kind: "bigint"},
+		"waiter_id":      {column: "waiter_id", kind: "bigint"},
+		"reservation_id": {column: "reservation_id", kind: "bigint"},
+		"shift_id":       {column: "shift_id", kind: "bigint"},
+		"status":         {column: "status", kind: "text"},
+		"created_at":     {column: "created_at", kind: "timestamp", sortable: true},
+	},
+	defaultSort: "-created_at",
+}
+
+func (r *Repository) ListOrders(ctx context.Context, q ListQuery) (domain.Page, error) {
+	return listPage(ctx, r.DB, orderList, q, scanOrder)
+}
+
+const orderColumns = `id, table_id, customer_id, waiter_id, reservation_id, shift_id, created_at, status, discount_percent, loyalty_discount_percent, service_charge_percent`
+
+func scanOrder(row rowScanner) (domain.Order, error) {
+	var o domain.Order
+	var customer sql.NullInt64
+	var reservation sql.NullInt64
+	var shift sql.NullInt64
+	if err := row.Scan(&o.ID, &o.TableID, &customer, &o.WaiterID, &reservation, &shift, &o.CreatedAt, &o.Status, &o.DiscountPercent, &o.LoyaltyDiscountPercent, &o.ServiceChargePercent); err != nil {
+		return o, err
+	}
+	if customer.Valid {
+		val := customer.Int64
+		o.CustomerID = &val
+	}
+	if reservation.Valid {
+		val := reservation.Int64
+		o.ReservationID = &val
+	}
+	if shift.Valid {
+		val := shift.Int64
+		o.ShiftID = &val
+	}
+	return o, nil
+}
+
+// GetOrderDetails returns the order with its items and status history.
+func (r *Repository) GetOrderDetails(ctx context.Context, id int64) (domain.OrderDetails, error) {
+	var d domain.OrderDetails
+	o, err := scanOrder(r.DB.QueryRowContext(ctx, `SELECT `+orderColumns+` FROM orders WHERE id=$1`, id))
+	if err != nil {
+		return d, err
+	}
+	d.Order = o
+	if d.Pricing, err = priceOrder(ctx, r.DB, id); err != nil {
+		return d, err
 	}
-	query := `SELECT id, table_id, customer_id, waiter_id, reservation_id, shift_id, created_at, status FROM orders`
-	args := []interface{}{}
-	if status != "" {
-		query += ` WHERE status=$1 ORDER BY created_at DESC LIMIT $2`
-		args = append(args, status, limit)
-	} else {
-		query += ` ORDER BY created_at DESC LIMIT $1`
-		args = append(args, limit)
+	if d.Items, err = r.ListOrderItems(ctx, id); err != nil {
+		return d, err
 	}
+	if d.StatusHistory, err = r.ListOrderStatusHistory(ctx, id); err != nil {
+		return d, err
+	}
+	return d, nil
+}
 
-	rows, err := r.DB.QueryContext(ctx, query, args...)
+func (r *Repository) ListOrderStatusHistory(ctx context.Context, orderID int64) ([]domain.OrderStatusChange, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT id, order_id, from_status, to_status, changed_at FROM order_status_history WHERE order_id=$1 ORDER BY changed_at, id`, orderID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var res []domain.Order
+	var res []domain.OrderStatusChange
 	for rows.Next() {
-		var o domain.Order
-		var customer sql.NullInt64
-		var reservation sql.NullInt64
-		var shift sql.NullInt64
-		if err := rows.Scan(&o.ID, &o.TableID, &customer, &o.WaiterID, &reservation, &shift, &o.CreatedAt, &o.Status); err != nil {
+		var h domain.OrderStatusChange
+		var from sql.NullString
+		if err := rows.Scan(&h.ID, &h.OrderID, &from, &h.ToStatus, &h.ChangedAt); err != nil {
 			return nil, err
 		}
-		if customer.Valid {
-			val := customer.Int64
-			o.CustomerID = &val
+		h.FromStatus = scanNullableString(from)
+		res = append(res, h)
+	}
+	return res, rows.Err()
+}
+
+func insertOrderStatusChange(ctx context.Context, tx *sql.Tx, orderID int64, from *string, to string) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO order_status_history(order_id, from_status, to_status) VALUES ($1,$2,$3)`, orderID, from, to)
+	return err
+}
+
+// CreateOrder stores the order with its items. Orders sent without a shift are
+// attached to the currently open one; orders with a customer get the discount
+// of the customer's VIP level.
+func (r *Repository) CreateOrder(ctx context.Context, o *domain.Order, items []domain.OrderItem) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		if o.ShiftID == nil {
+			shiftID, err := r.currentShiftID(ctx, tx)
+			if err != nil {
+				return err
+			}
+			o.ShiftID = shiftID
+		} else if err := lockOpenShift(ctx, tx, *o.ShiftID); err != nil {
+			return err
+		}
+
+		o.ServiceChargePercent = r.Cfg.ServiceChargePercent
+		if o.CustomerID != nil {
+			discount, err := r.customerDiscount(ctx, tx, *o.CustomerID)
+			if err != nil {
+				return err
+			}
+			o.LoyaltyDiscountPercent = discount
+		}
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO orders(table_id, customer_id, waiter_id, reservation_id, shift_id, status, service_charge_percent, loyalty_discount_percent)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+			RETURNING id, created_at`,
+			o.TableID, o.CustomerID, o.WaiterID, o.ReservationID, o.ShiftID, o.Status, o.ServiceChargePercent, o.LoyaltyDiscountPercent).
+			Scan(&o.ID, &o.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if err := insertOrderStatusChange(ctx, tx, o.ID, nil, o.Status); err != nil {
+			return err
 		}
-		if reservation.Valid {
-			val := reservation.Int64
-			o.ReservationID = &val
+
+		for i := range items {
+			if err := upsertOrderItem(ctx, tx, o.ID, &items[i]); err != nil {
+				return err
+			}
 		}
-		if shift.Valid {
-			val := shift.Int64
-			o.ShiftID = &val
+		return nil
+	})
+}
+
+// currentShiftID locks the open shift so it cannot be closed before the order commits.
+// It returns nil when no shift is open and the deployment allows orders without a shift.
+func (r *Repository) currentShiftID(ctx context.Context, tx *sql.Tx) (*int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM shifts WHERE status='opened' FOR SHARE`).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		if r.Cfg.RequireOpenShift {
+			return nil, ErrNoOpenShift
 		}
-		res = append(res, o)
+		return nil, nil
 	}
-	return res, rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
 }
 
-func (r *Repository) CreateOrder(ctx context.Context, o *domain.Order, items []domain.OrderItem) error {
-	tx, err := r.DB.BeginTx(ctx, nil)
+// lockOpenShift checks that a shift named by the client is open and locks it
+// like currentShiftID, so a closed shift's revenue never gains orders.
+func lockOpenShift(ctx context.Context, tx *sql.Tx, shiftID int64) error {
+	var status string
+	err := tx.QueryRowContext(ctx, `SELECT status FROM shifts WHERE id=$1 FOR SHARE`, shiftID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: shift %d does not exist", ErrNoOpenShift, shiftID)
+	}
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-		}
-	}()
+	if status != "opened" {
+		return fmt.Errorf("%w: shift %d", ErrShiftAlreadyClosed, shiftID)
+	}
+	return nil
+}
 
-	err = tx.QueryRowContext(ctx, `
-		INSERT INTO orders(table_id, customer_id, waiter_id, reservation_id, shift_id, status)
-		VALUES ($1,$2,$3,$4,$5,$6)
-		RETURNING id, created_at`,
-		o.TableID, o.CustomerID, o.WaiterID, o.ReservationID, o.ShiftID, o.Status).
-		Scan(&o.ID, &o.CreatedAt)
-	if err != nil {
+// UpdateOrderStatus moves the order along the status machine, records the transition
+// and keeps ingredient stock in sync with it in the same transaction. Warnings come
+// from the configured stock shortage policy.
+func (r *Repository) UpdateOrderStatus(ctx context.Context, id int64, status string) ([]string, error) {
+	var warnings []string
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		warnings, err = r.transitionOrder(ctx, tx, id, status)
 		return err
+	})
+	return warnings, err
+}
+
+func (r *Repository) transitionOrder(ctx context.Context, tx *sql.Tx, id int64, status string) ([]string, error) {
+	var current string
+	var deducted bool
+	if err := tx.QueryRowContext(ctx, `SELECT status, stock_deducted FROM orders WHERE id=$1 FOR UPDATE`, id).Scan(&current, &deducted); err != nil {
+		return nil, err
 	}
+	if err := orderStatuses.check(current, status); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE orders SET status=$1 WHERE id=$2`, status, id); err != nil {
+		return nil, err
+	}
+	if err := insertOrderStatusChange(ctx, tx, id, &current, status); err != nil {
+		return nil, err
+	}
+	return r.syncOrderStock(ctx, tx, id, status, deducted)
+}
 
-	for _, item := range items {
-		_, err = tx.ExecContext(ctx, `
-			INSERT INTO order_items(order_id, dish_id, quantity, price_at_moment, comment)
-			VALUES ($1,$2,$3,$4,$5)
-			ON CONFLICT (order_id, dish_id) DO UPDATE SET quantity=EXCLUDED.quantity, price_at_moment=EXCLUDED.price_at_moment, comment=EXCLUDED.comment`,
-			o.ID, item.DishID, item.Quantity, item.PriceAtMoment, item.Comment)
+// AddOrderItem adds the item or replaces its quantity. When the order's stock is
+// already deducted the difference is consumed or returned in the same
+// transaction; warnings come from the stock shortage policy.
+func (r *Repository) AddOrderItem(ctx context.Context, orderID int64, item *domain.OrderItem) ([]string, error) {
+	var warnings []string
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		deducted, err := lockEditableOrder(ctx, tx, orderID)
 		if err != nil {
 			return err
 		}
+		var previous int
+		err = tx.QueryRowContext(ctx, `SELECT quantity FROM order_items WHERE order_id=$1 AND dish_id=$2`, orderID, item.DishID).Scan(&previous)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if err := upsertOrderItem(ctx, tx, orderID, item); err != nil {
+			return err
+		}
+		if !deducted {
+			return nil
+		}
+		warnings, err = r.syncOrderItemStock(ctx, tx, orderID, item.DishID, item.Quantity-previous)
+		return err
+	})
+	return warnings, err
+}
+
+// lockEditableOrder locks the order row and refuses item changes once the order
+// is closed or cancelled. It reports whether the order's stock is deducted.
+func lockEditableOrder(ctx context.Context, tx *sql.Tx, orderID int64) (bool, error) {
+	var status string
+	var deducted bool
+	if err := tx.QueryRowContext(ctx, `SELECT status, stock_deducted FROM orders WHERE id=$1 FOR UPDATE`, orderID).Scan(&status, &deducted); err != nil {
+		return false, err
+	}
+	if status == "closed" || status == "cancelled" {
+		return false, fmt.Errorf("%w: order %d is %s", ErrOrderFinalized, orderID, status)
+	}
+	return deducted, nil
+}
+
+// upsertOrderItem prices the item from the menu and stores it. A client price is
+// only kept when the acting employee holds orders:override_price and gives a
+// reason; that employee is recorded as the approver.
+func upsertOrderItem(ctx context.Context, tx *sql.Tx, orderID int64, item *domain.OrderItem) error {
+	var price float64
+	var active, orderable bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT d.price, d.is_active, v.can_be_ordered
+		FROM dishes d
+		JOIN view_dishes_availability v ON v.id = d.id
+		WHERE d.id=$1`, item.DishID).Scan(&price, &active, &orderable)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: dish %d does not exist", ErrDishUnavailable, item.DishID)
+	}
+	if err != nil {
+		return err
+	}
+	if !active {
+		return fmt.Errorf("%w: dish %d is inactive", ErrDishUnavailable, item.DishID)
+	}
+	if !orderable {
+		return fmt.Errorf("%w: dish %d has unavailable ingredients", ErrDishUnavailable, item.DishID)
 	}
-	return nil
-}
 
-func (r *Repository) UpdateOrderStatus(ctx context.Context, id int64, status string) error {
-	_, err := r.DB.ExecContext(ctx, `UPDATE orders SET status=$1 WHERE id=$2`, status, id)
-	return err
+	if item.PriceOverrideBy == nil && item.PriceOverrideReason == "" {
+		item.PriceAtMoment = price
+		item.PriceOverrideReason = ""
+		item.DiscountPercent = 0
+	} else if err := checkPriceOverride(ctx, tx, item); err != nil {
+		return err
+	}
+
+	item.OrderID = orderID
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO order_items(order_id, dish_id, quantity, price_at_moment, comment, price_override_by, price_override_reason, discount_percent)
+		VALUES ($1,$2,$3,$4,$5,$6,NULLIF($7,''),$8)
+		ON CONFLICT (order_id, dish_id) DO UPDATE SET quantity=EXCLUDED.quantity, price_at_moment=EXCLUDED.price_at_moment, comment=EXCLUDED.comment,
+			price_override_by=EXCLUDED.price_override_by, price_override_reason=EXCLUDED.price_override_reason, discount_percent=EXCLUDED.discount_percent
+		RETURNING id`,
+		orderID, item.DishID, item.Quantity, item.PriceAtMoment, item.Comment, item.PriceOverrideBy, item.PriceOverrideReason, item.DiscountPercent).
+		Scan(&item.ID)
 }
 
-func (r *Repository) AddOrderItem(ctx context.Context, orderID int64, item domain.OrderItem) error {
-	_, err := r.DB.ExecContext(ctx, `
-		INSERT INTO order_items(order_id, dish_id, quantity, price_at_moment, comment)
-		VALUES ($1,$2,$3,$4,$5)
-		ON CONFLICT (order_id, dish_id) DO UPDATE SET quantity=EXCLUDED.quantity, price_at_moment=EXCLUDED.price_at_moment, comment=EXCLUDED.comment`,
-		orderID, item.DishID, item.Quantity, item.PriceAtMoment, item.Comment)
-	return err
+func checkPriceOverride(ctx context.Context, tx *sql.Tx, item *domain.OrderItem) error {
+	if item.PriceOverrideReason == "" {
+		return fmt.Errorf("%w: price_override_reason is required", ErrOverrideForbidden)
+	}
+	if item.PriceAtMoment < 0 {
+		return fmt.Errorf("%w: price must not be negative", ErrOverrideForbidden)
+	}
+	if item.DiscountPercent < 0 || item.DiscountPercent > 100 {
+		return fmt.Errorf("%w: discount_percent must be between 0 and 100", ErrOverrideForbidden)
+	}
+	approver, ok := EmployeeFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("%w: no authenticated employee", ErrOverrideForbidden)
+	}
+	if item.PriceOverrideBy != nil && *item.PriceOverrideBy != approver {
+		return fmt.Errorf("%w: price_override_by must be the acting employee", ErrOverrideForbidden)
+	}
+	ok, err := employeeCan(ctx, tx, approver, "orders:override_price")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: employee %d cannot approve price overrides", ErrOverrideForbidden, approver)
+	}
+	item.PriceOverrideBy = &approver
+	return nil
 }
 
 func (r *Repository) ListOrderItems(ctx context.Context, orderID int64) ([]domain.OrderItem, error) {
-	rows, err := r.DB.QueryContext(ctx, `SELECT id, order_id, dish_id, quantity, price_at_moment, COALESCE(comment,'') FROM order_items WHERE order_id=$1`, orderID)
+	rows, err := r.DB.QueryContext(ctx, `SELECT id, order_id, dish_id, quantity, price_at_moment, COALESCE(comment,''), price_override_by, COALESCE(price_override_reason,''), discount_percent FROM order_items WHERE order_id=$1`, orderID)
 	if err != nil {
 		return nil, err
 	}
@@ -408,37 +789,40 @@ func (r *Repository) ListOrderItems(ctx context.Context, orderID int64) ([]domai
 	var res []domain.OrderItem
 	for rows.Next() {
 		var oi domain.OrderItem
-		if err := rows.Scan(&oi.ID, &oi.OrderID, &oi.DishID, &oi.Quantity, &oi.PriceAtMoment, &oi.Comment); err != nil {
+		var overrideBy sql.NullInt64
+		if err := rows.Scan(&oi.ID, &oi.OrderID, &oi.DishID, &oi.Quantity, &oi.PriceAtMoment, &oi.Comment, &overrideBy, &oi.PriceOverrideReason, &oi.DiscountPercent); err != nil {
 			return nil, err
 		}
+		if overrideBy.Valid {
+			val := overrideBy.Int64
+			oi.PriceOverrideBy = &val
+		}
 		res = append(res, oi)
 	}
 	return res, rows.Err()
 }
 
-func (r *Repository) DeleteOrderItem(ctx context.Context, id int64) error {
-	_, err := r.DB.ExecContext(ctx, `DELETE FROM order_items WHERE id=$1`, id)
-	return err
-}
-
-// Payments
-func (r *Repository) UpsertPayment(ctx context.Context, p *domain.Payment) error {
-	return r.DB.QueryRowContext(ctx, `
-		INSERT INTO payments(order_id, amount, method, status, paid_at)
-		VALUES ($1,$2,$3,$4,$5)
-		ON CONFLICT (order_id) DO UPDATE SET amount=EXCLUDED.amount, method=EXCLUDED.method, status=EXCLUDED.status, paid_at=EXCLUDED.paid_at
-		RETURNING id`,
-		p.OrderID, p.Amount, p.Method, p.Status, p.PaidAt).Scan(&p.ID)
-}
-
-func (r *Repository) DeletePayment(ctx context.Context, orderID int64) error {
-	_, err := r.DB.ExecContext(ctx, `DELETE FROM payments WHERE order_id=$1`, orderID)
-	return err
+func (r *Repository) DeleteOrderItem(ctx context.Context, orderID, itemID int64) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		deducted, err := lockEditableOrder(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+		var dishID int64
+		var quantity int
+		err = tx.QueryRowContext(ctx, `DELETE FROM order_items WHERE id=$1 AND order_id=$2 RETURNING dish_id, quantity`, itemID, orderID).
+			Scan(&dishID, &quantity)
+		if err != nil || !deducted {
+			return err
+		}
+		_, err = r.syncOrderItemStock(ctx, tx, orderID, dishID, -quantity)
+		return err
+	})
 }
 
 // Reports
 func (r *Repository) GetShiftRevenue(ctx context.Context) ([]domain.ShiftRevenue, error) {
-	rows, err := r.DB.QueryContext(ctx, `SELECT shift_id, opened_at, closed_at, orders_count, total_revenue, avg_check FROM view_shift_revenue ORDER BY shift_id DESC LIMIT 100`)
+	rows, err := r.DB.QueryContext(ctx, `SELECT shift_id, opened_at, closed_at, orders_count, total_revenue, avg_check, total_refunds FROM view_shift_revenue ORDER BY shift_id DESC LIMIT 100`)
 	if err != nil {
 		return nil, err
 	}
@@ -448,7 +832,7 @@ func (r *Repository) GetShiftRevenue(ctx context.Context) ([]domain.ShiftRevenue
 		var sr domain.ShiftRevenue
 		var closed sql.NullTime
 		var avg sql.NullFloat64
-		if err := rows.Scan(&sr.ShiftID, &sr.OpenedAt, &closed, &sr.OrdersCount, &sr.TotalRevenue, &avg); err != nil {
+		if err := rows.Scan(&sr.ShiftID, &sr.OpenedAt, &closed, &sr.OrdersCount, &sr.TotalRevenue, &avg, &sr.TotalRefunds); err != nil {
 			return nil, err
 		}
 		if closed.Valid {
@@ -503,59 +887,6 @@ func (r *Repository) GetDishesAvailability(ctx context.Context) ([]domain.DishAv
 	return res, rows.Err()
 }
 
-// Batch import products
-func (r *Repository) BatchImportProducts(ctx context.Context, products []domain.Product) (int, error) {
-	tx, err := r.DB.BeginTx(ctx, nil)
-	if err != nil {
-		return 0, err
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-		}
-	}()
-
-	inserted := 0
-	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO products(name, unit, cost_price, is_available)
-		VALUES ($1,$2,$3,$4)
-		ON CONFLICT (name) DO UPDATE SET unit=EXCLUDED.unit, cost_price=EXCLUDED.cost_price, is_available=EXCLUDED.is_available`)
-	if err != nil {
-		return 0, err
-	}
-	defer stmt.Close()
-
-	for _, p := range products {
-		if p.Name == "" || p.Unit == "" {
-			logErr(ctx, tx, "product", p, errors.New("name and unit are required"))
-			continue
-		}
-		if _, err = stmt.ExecContext(ctx, p.Name, p.Unit, p.CostPrice, p.IsAvailable); err != nil {
-			logErr(ctx, tx, "product", p, err)
-			continue
-		}
-		inserted++
-	}
-	return inserted, nil
-}
-
-func logErr(ctx context.Context, tx *sql.Tx, entity string, raw interface{}, err error) {
-	_, _ = tx.ExecContext(ctx, `
-		INSERT INTO import_errors(entity, raw_data, error_message)
-		VALUES ($1, to_jsonb($2::json), $3)`,
-		entity, rawAsJSON(raw), err.Error())
-}
-
-func rawAsJSON(raw interface{}) string {
-	b, err := json.Marshal(raw)
-	if err != nil {
-		return "{}"
-	}
-	return string(b)
-}
-
 func nullableNumber(f *float64) string {
 	if f == nil {
 		return "null"
@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/example/rms/internal/domain"
+)
+
+// restoreSpec says how a record is written back from a to_jsonb snapshot and
+// which rows a delete of the record takes with it: children removed by ON
+// DELETE CASCADE and references cleared by ON DELETE SET NULL. restore gets
+// exists=true when the record is still there and must be updated in place.
+type restoreSpec struct {
+	restore func(ctx context.Context, tx *sql.Tx, snapshot []byte, exists bool) error
+	deleted []childRef
+	nulled  []childRef
+}
+
+type childRef struct {
+	table  string
+	column string
+}
+
+var restorable = map[string]restoreSpec{
+	"customers": {
+		restore: restoreCustomer,
+		deleted: []childRef{{"reservations", "customer_id"}},
+		nulled:  []childRef{{"orders", "customer_id"}},
+	},
+	"reservations": {
+		restore: restoreReservation,
+		nulled:  []childRef{{"orders", "reservation_id"}},
+	},
+	"dishes": {
+		restore: restoreDish,
+		deleted: []childRef{{"dish_ingredients", "dish_id"}},
+	},
+	"dish_ingredients": {
+		restore: restoreDishIngredient,
+	},
+	"products": {
+		restore: restoreProduct,
+	},
+}
+
+// RestoreRecord writes the record back to the state stored in an audit_log
+// row: the new data of an insert or update, or the old data of a delete. With
+// auditID zero the record is un-deleted from its last DELETE snapshot. Rows
+// removed or unlinked by the same delete, found by its request id, are
+// restored too. The writes go through the same checks as the API and run in
+// an ordinary audited transaction, so the restore shows up in the audit log.
+func (r *Repository) RestoreRecord(ctx context.Context, table string, recordID, auditID int64) (domain.RestoreResult, error) {
+	res := domain.RestoreResult{Table: table, RecordID: recordID, Related: []domain.RecordRef{}}
+	spec, ok := restorable[table]
+	if !ok {
+		return res, fmt.Errorf("%w: %s cannot be restored", ErrInvalidRestore, table)
+	}
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id=$1)`, recordID).Scan(&exists); err != nil {
+			return err
+		}
+		var v auditVersion
+		var err error
+		if auditID == 0 {
+			if exists {
+				return fmt.Errorf("%w: %s %d was not deleted", ErrRestoreConflict, table, recordID)
+			}
+			v, err = lastDelete(ctx, tx, table, recordID)
+			if err != nil {
+				return err
+			}
+		} else {
+			v, err = auditVersionByID(ctx, tx, auditID)
+			if err != nil {
+				return err
+			}
+			if v.table != table || v.recordID != recordID {
+				return fmt.Errorf("%w: audit entry %d belongs to %s %d", ErrInvalidRestore, auditID, v.table, v.recordID)
+			}
+		}
+		res.FromAuditID = v.id
+		if err := spec.restore(ctx, tx, v.snapshot(), exists); err != nil {
+			return err
+		}
+		if v.operation == "DELETE" && !exists {
+			if err := restoreDeleted(ctx, tx, table, recordID, v.requestID, &res.Related); err != nil {
+				return err
+			}
+		}
+		var data []byte
+		if err := tx.QueryRowContext(ctx, `SELECT to_jsonb(t) FROM `+table+` t WHERE id=$1`, recordID).Scan(&data); err != nil {
+			return err
+		}
+		res.Data = data
+		return nil
+	})
+	return res, err
+}
+
+type auditVersion struct {
+	id        int64
+	table     string
+	recordID  int64
+	operation string
+	requestID sql.NullString
+	oldData   []byte
+	newData   []byte
+}
+
+func (v auditVersion) snapshot() []byte {
+	if v.operation == "DELETE" {
+		return v.oldData
+	}
+	return v.newData
+}
+
+const auditVersionColumns = `id, table_name, record_id, operation, request_id, old_data, new_data`
+
+func scanAuditVersion(row rowScanner) (auditVersion, error) {
+	var v auditVersion
+	err := row.Scan(&v.id, &v.table, &v.recordID, &v.operation, &v.requestID, &v.oldData, &v.newData)
+	return v, err
+}
+
+func auditVersionByID(ctx context.Context, tx *sql.Tx, auditID int64) (auditVersion, error) {
+	return scanAuditVersion(tx.QueryRowContext(ctx, `SELECT `+auditVersionColumns+` FROM audit_log WHERE id=$1`, auditID))
+}
+
+func lastDelete(ctx context.Context, tx *sql.Tx, table string, recordID int64) (auditVersion, error) {
+	v, err := scanAuditVersion(tx.QueryRowContext(ctx, `
+		SELECT `+auditVersionColumns+` FROM audit_log
+		WHERE table_name=$1 AND record_id=$2 AND operation='DELETE'
+		ORDER BY id DESC LIMIT 1`, table, recordID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return v, fmt.Errorf("%w: no deletion of %s %d is recorded", ErrInvalidRestore, table, recordID)
+	}
+	return v, err
+}
+
+// decodeSnapshot reads columns of a to_jsonb snapshot of table into dest,
+// letting PostgreSQL convert the values to the column types.
+func decodeSnapshot(ctx context.Context, tx *sql.Tx, table, columns string, snapshot []byte, dest ...interface{}) error {
+	return tx.QueryRowContext(ctx, `SELECT `+columns+` FROM jsonb_populate_record(NULL::`+table+`, $1::jsonb)`, snapshot).Scan(dest...)
+}
+
+func restoreCustomer(ctx context.Context, tx *sql.Tx, snapshot []byte, exists bool) error {
+	var c domain.Customer
+	var email sql.NullString
+	err := decodeSnapshot(ctx, tx, "customers", "id, full_name, phone, email, vip_level, created_at", snapshot,
+		&c.ID, &c.FullName, &c.Phone, &email, &c.VIPLevel, &c.CreatedAt)
+	if err != nil {
+		return err
+	}
+	c.Email = scanNullableString(email)
+	if exists {
+		return updateCustomer(ctx, tx, c.ID, &c)
+	}
+	return insertCustomer(ctx, tx, &c, true)
+}
+
+// restoreReservation puts a deleted reservation back as it was, subject to the
+// overlap check. Reservations only change status through the API, so a live
+// reservation is restored by moving it to the snapshot's status along the
+// status machine.
+func restoreReservation(ctx context.Context, tx *sql.Tx, snapshot []byte, exists bool) error {
+	var rsv domain.Reservation
+	err := decodeSnapshot(ctx, tx, "reservations", "id, customer_id, table_id, reserved_from, reserved_to, status, created_at", snapshot,
+		&rsv.ID, &rsv.CustomerID, &rsv.TableID, &rsv.ReservedFrom, &rsv.ReservedTo, &rsv.Status, &rsv.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return insertReservation(ctx, tx, &rsv, true)
+	}
+	var current string
+	if err := tx.QueryRowContext(ctx, `SELECT status FROM reservations WHERE id=$1 FOR UPDATE`, rsv.ID).Scan(&current); err != nil {
+		return err
+	}
+	if current == rsv.Status {
+		return nil
+	}
+	return transitionReservation(ctx, tx, rsv.ID, rsv.Status)
+}
+
+func restoreDish(ctx context.Context, tx *sql.Tx, snapshot []byte, exists bool) error {
+	var d domain.Dish
+	err := decodeSnapshot(ctx, tx, "dishes", "id, category_id, name, price, cook_time_minutes, is_active, COALESCE(description, '')", snapshot,
+		&d.ID, &d.CategoryID, &d.Name, &d.Price, &d.CookTimeMinutes, &d.IsActive, &d.Description)
+	if err != nil {
+		return err
+	}
+	return saveDish(ctx, tx, &d, true)
+}
+
+func restoreDishIngredient(ctx context.Context, tx *sql.Tx, snapshot []byte, exists bool) error {
+	var di domain.DishIngredient
+	err := decodeSnapshot(ctx, tx, "dish_ingredients", "id, dish_id, product_id, quantity", snapshot,
+		&di.ID, &di.DishID, &di.ProductID, &di.Quantity)
+	if err != nil {
+		return err
+	}
+	return saveDishIngredient(ctx, tx, &di, true)
+}
+
+// restoreProduct writes the product back. A live product keeps the
+// availability its stock gives it. A deleted product never had stock
+// movements (the ledger restricts the delete), so its stock starts from the
+// ledger again, which also sets its availability.
+func restoreProduct(ctx context.Context, tx *sql.Tx, snapshot []byte, exists bool) error {
+	var p domain.Product
+	var cost sql.NullFloat64
+	err := decodeSnapshot(ctx, tx, "products", "id, name, unit, cost_price, is_available", snapshot,
+		&p.ID, &p.Name, &p.Unit, &cost, &p.IsAvailable)
+	if err != nil {
+		return err
+	}
+	p.CostPrice = nullableFloat(cost)
+	if err := saveProduct(ctx, tx, &p, true); err != nil || exists {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO product_stock(product_id, quantity)
+		SELECT $1, COALESCE(SUM(quantity_delta), 0) FROM stock_movements WHERE product_id=$1
+		ON CONFLICT (product_id) DO NOTHING`, p.ID)
+	return err
+}
+
+// restoreDeleted undoes the side effects of deleting table/id: the audit rows
+// written by the same request share its request id. Deletes made outside the
+// API carry no request id and only the record itself is restored.
+func restoreDeleted(ctx context.Context, tx *sql.Tx, table string, id int64, requestID sql.NullString, related *[]domain.RecordRef) error {
+	if !requestID.Valid {
+		return nil
+	}
+	spec := restorable[table]
+	key := strconv.FormatInt(id, 10)
+	for _, child := range spec.deleted {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT `+auditVersionColumns+` FROM audit_log
+			WHERE table_name=$1 AND operation='DELETE' AND request_id=$2 AND old_data->>$3 = $4
+			ORDER BY id`, child.table, requestID.String, child.column, key)
+		if err != nil {
+			return err
+		}
+		var children []auditVersion
+		for rows.Next() {
+			v, err := scanAuditVersion(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			children = append(children, v)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		for _, v := range children {
+			if err := restorable[v.table].restore(ctx, tx, v.oldData, false); err != nil {
+				return err
+			}
+			*related = append(*related, domain.RecordRef{Table: v.table, ID: v.recordID, Action: "restored"})
+			if err := restoreDeleted(ctx, tx, v.table, v.recordID, requestID, related); err != nil {
+				return err
+			}
+		}
+	}
+	for _, child := range spec.nulled {
+		rows, err := tx.QueryContext(ctx, `
+			UPDATE `+child.table+` SET `+child.column+`=$1
+			WHERE `+child.column+` IS NULL AND id IN (
+				SELECT record_id FROM audit_log
+				WHERE table_name=$2 AND operation='UPDATE' AND request_id=$3
+				  AND old_data->>$4 = $5 AND new_data->>$4 IS NULL)
+			RETURNING id`, id, child.table, requestID.String, child.column, key)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			ref := domain.RecordRef{Table: child.table, Action: "relinked"}
+			if err := rows.Scan(&ref.ID); err != nil {
+				rows.Close()
+				return err
+			}
+			*related = append(*related, ref)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/example/rms/internal/domain"
+)
+
+// Permissions lists every action a role can be granted. A grant may also be
+// "*" for everything or "<resource>:*" for every action on a resource.
+var Permissions = []string{
+	"customers:read", "customers:create", "customers:update", "customers:delete",
+	"employees:read", "employees:create", "employees:update", "employees:delete",
+	"roles:read", "roles:create", "roles:update", "roles:delete",
+	"tables:read", "tables:create", "tables:update", "tables:delete",
+	"menu:read", "menu:create", "menu:update", "menu:delete",
+	"dishes:read", "dishes:create", "dishes:update", "dishes:delete",
+	"products:read", "products:create", "products:update", "products:delete",
+	"stock:read", "stock:create",
+	"reservations:read", "reservations:create", "reservations:update", "reservations:delete",
+	"orders:read", "orders:create", "orders:update", "orders:delete", "orders:discount", "orders:override_price",
+	"payments:read", "payments:create", "payments:delete", "payments:refund",
+	"shifts:read", "shifts:open", "shifts:close",
+	"reports:read",
+	"import:read", "import:create", "import:update",
+	"audit:read", "audit:restore",
+}
+
+// grantMatches is the SQL condition for a role_permissions row granting the permission bound to $2.
+const grantMatches = `(rp.permission = $2 OR rp.permission = '*' OR rp.permission = split_part($2, ':', 1) || ':*')`
+
+// Authorize checks that the employee is active and one of their role's grants
+// covers the permission.
+func (r *Repository) Authorize(ctx context.Context, employeeID int64, permission string) error {
+	var allowed bool
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM role_permissions rp WHERE rp.role_id = e.role_id AND `+grantMatches+`)
+		FROM employees e
+		WHERE e.id=$1 AND e.is_active`, employeeID, permission).Scan(&allowed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: employee %d is unknown or inactive", ErrUnauthenticated, employeeID)
+	}
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s", ErrPermissionDenied, permission)
+	}
+	return nil
+}
+
+// employeeCan reports whether the employee is active and holds the permission;
+// it is used for approvals recorded inside a transaction.
+func employeeCan(ctx context.Context, tx *sql.Tx, employeeID int64, permission string) (bool, error) {
+	var ok bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM role_permissions rp WHERE rp.role_id = e.role_id AND `+grantMatches+`)
+		FROM employees e
+		WHERE e.id=$1 AND e.is_active`, employeeID, permission).Scan(&ok)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return ok, err
+}
+
+// Roles
+func (r *Repository) ListRoles(ctx context.Context) ([]domain.Role, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT ro.id, ro.name, COALESCE(ro.description,''), ro.created_at, ro.is_system,
+		       COALESCE(array_agg(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}')
+		FROM roles ro
+		LEFT JOIN role_permissions rp ON rp.role_id = ro.id
+		GROUP BY ro.id
+		ORDER BY ro.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Role
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, role)
+	}
+	return res, rows.Err()
+}
+
+func (r *Repository) GetRole(ctx context.Context, id int64) (domain.Role, error) {
+	return scanRole(r.DB.QueryRowContext(ctx, `
+		SELECT ro.id, ro.name, COALESCE(ro.description,''), ro.created_at, ro.is_system,
+		       COALESCE(array_agg(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}')
+		FROM roles ro
+		LEFT JOIN role_permissions rp ON rp.role_id = ro.id
+		WHERE ro.id=$1
+		GROUP BY ro.id`, id))
+}
+
+func scanRole(row rowScanner) (domain.Role, error) {
+	var role domain.Role
+	var perms pq.StringArray
+	if err := row.Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt, &role.IsSystem, &perms); err != nil {
+		return role, err
+	}
+	role.Permissions = []string(perms)
+	return role, nil
+}
+
+// CreateRole stores a custom role with its grants; system roles are only created by migrations.
+func (r *Repository) CreateRole(ctx context.Context, role *domain.Role) error {
+	if err := validatePermissions(role.Permissions); err != nil {
+		return err
+	}
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		role.IsSystem = false
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO roles(name, description, is_system) VALUES ($1, NULLIF($2,''), FALSE)
+			RETURNING id, created_at`, role.Name, role.Description).Scan(&role.ID, &role.CreatedAt)
+		if err != nil {
+			return err
+		}
+		return replaceRolePermissions(ctx, tx, role.ID, role.Permissions)
+	})
+}
+
+// UpdateRole replaces the role's name, description and grants. System roles cannot be changed.
+func (r *Repository) UpdateRole(ctx context.Context, id int64, role *domain.Role) error {
+	if err := validatePermissions(role.Permissions); err != nil {
+		return err
+	}
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		if err := lockCustomRole(ctx, tx, id); err != nil {
+			return err
+		}
+		err := tx.QueryRowContext(ctx, `
+			UPDATE roles SET name=$1, description=NULLIF($2,'') WHERE id=$3
+			RETURNING created_at, is_system`, role.Name, role.Description, id).Scan(&role.CreatedAt, &role.IsSystem)
+		if err != nil {
+			return err
+		}
+		role.ID = id
+		return replaceRolePermissions(ctx, tx, id, role.Permissions)
+	})
+}
+
+// DeleteRole removes a custom role; roles still assigned to employees are kept by the foreign key.
+func (r *Repository) DeleteRole(ctx context.Context, id int64) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		if err := lockCustomRole(ctx, tx, id); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM roles WHERE id=$1`, id)
+		return err
+	})
+}
+
+func lockCustomRole(ctx context.Context, tx *sql.Tx, id int64) error {
+	var system bool
+	if err := tx.QueryRowContext(ctx, `SELECT is_system FROM roles WHERE id=$1 FOR UPDATE`, id).Scan(&system); err != nil {
+		return err
+	}
+	if system {
+		return fmt.Errorf("%w: role %d", ErrSystemRole, id)
+	}
+	return nil
+}
+
+func replaceRolePermissions(ctx context.Context, tx *sql.Tx, roleID int64, permissions []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id=$1`, roleID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO role_permissions(role_id, permission)
+		SELECT $1, p FROM unnest($2::text[]) AS p
+		ON CONFLICT DO NOTHING`, roleID, pq.Array(permissions))
+	return err
+}
+
+func validatePermissions(permissions []string) error {
+	known := make(map[string]bool, len(Permissions))
+	resources := make(map[string]bool)
+	for _, p := range Permissions {
+		known[p] = true
+		resources[strings.SplitN(p, ":", 2)[0]] = true
+	}
+	for _, p := range permissions {
+		if p == "*" || known[p] {
+			continue
+		}
+		if resource, ok := strings.CutSuffix(p, ":*"); ok && resources[resource] {
+			continue
+		}
+		return fmt.Errorf("%w: unknown permission %q", ErrInvalidPermission, p)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/example/rms/internal/domain"
+)
+
+const shiftColumns = `id, opened_by, closed_by, opened_at, closed_at, status, COALESCE(note,''), expected_revenue, actual_revenue, revenue_discrepancy`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanShift(row rowScanner) (domain.Shift, error) {
+	var s domain.Shift
+	var closedBy sql.NullInt64
+	var closedAt sql.NullTime
+	var expected, actual, discrepancy sql.NullFloat64
+	if err := row.Scan(&s.ID, &s.OpenedBy, &closedBy, &s.OpenedAt, &closedAt, &s.Status, &s.Note, &expected, &actual, &discrepancy); err != nil {
+		return s, err
+	}
+	if closedBy.Valid {
+		val := closedBy.Int64
+		s.ClosedBy = &val
+	}
+	if closedAt.Valid {
+		val := closedAt.Time
+		s.ClosedAt = &val
+	}
+	s.ExpectedRevenue = nullableFloat(expected)
+	s.ActualRevenue = nullableFloat(actual)
+	s.Discrepancy = nullableFloat(discrepancy)
+	return s, nil
+}
+
+func nullableFloat(f sql.NullFloat64) *float64 {
+	if f.Valid {
+		val := f.Float64
+		return &val
+	}
+	return nil
+}
+
+// Shifts
+func (r *Repository) ListShifts(ctx context.Context, limit int) ([]domain.Shift, error) {
+	if limit <= 0 || limit > 300 {
+		limit = 100
+	}
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+shiftColumns+` FROM shifts ORDER BY opened_at DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Shift
+	for rows.Next() {
+		s, err := scanShift(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	return res, rows.Err()
+}
+
+// GetCurrentShift returns the open shift or sql.ErrNoRows when none is open.
+func (r *Repository) GetCurrentShift(ctx context.Context) (domain.Shift, error) {
+	return scanShift(r.DB.QueryRowContext(ctx, `SELECT `+shiftColumns+` FROM shifts WHERE status='opened'`))
+}
+
+func (r *Repository) GetShift(ctx context.Context, id int64) (domain.Shift, error) {
+	return scanShift(r.DB.QueryRowContext(ctx, `SELECT `+shiftColumns+` FROM shifts WHERE id=$1`, id))
+}
+
+// OpenShift starts a new shift; uq_shifts_single_open guarantees there is at most one.
+func (r *Repository) OpenShift(ctx context.Context, s *domain.Shift) error {
+	err := r.execReturning(ctx, `
+		INSERT INTO shifts(opened_by, status, note)
+		VALUES ($1, 'opened', NULLIF($2,''))
+		RETURNING id, opened_at, status`,
+		[]interface{}{s.OpenedBy, s.Note}, &s.ID, &s.OpenedAt, &s.Status)
+	if isUniqueViolation(err, "uq_shifts_single_open") {
+		return ErrShiftAlreadyOpen
+	}
+	return err
+}
+
+// CloseShift closes an open shift, storing the counted revenue next to the one
+// computed by get_shift_revenue so the discrepancy is kept with the shift.
+func (r *Repository) CloseShift(ctx context.Context, id, closedBy int64, actualRevenue float64, note string) (domain.Shift, error) {
+	var s domain.Shift
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		s, err = scanShift(tx.QueryRowContext(ctx, `
+			UPDATE shifts
+			SET status='closed', closed_by=$1, closed_at=now(), actual_revenue=$2,
+			    expected_revenue=get_shift_revenue(id), note=COALESCE(NULLIF($3,''), note)
+			WHERE id=$4 AND status='opened'
+			RETURNING `+shiftColumns,
+			closedBy, actualRevenue, note, id))
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, getErr := r.GetShift(ctx, id); getErr != nil {
+			return s, getErr
+		}
+		return s, ErrShiftAlreadyClosed
+	}
+	return s, err
+}
@@ -0,0 +1,35 @@
+package repository
+
+import "fmt"
+
+// statusMachine lists the statuses reachable from each status; terminal statuses map to nil.
+type statusMachine map[string][]string
+
+var orderStatuses = statusMachine{
+	"new":         {"in_progress", "cancelled"},
+	"in_progress": {"closed", "cancelled"},
+	"closed":      nil,
+	"cancelled":   nil,
+}
+
+var reservationStatuses = statusMachine{
+	"new":       {"confirmed", "cancelled"},
+	"confirmed": {"completed", "cancelled", "no_show"},
+	"completed": nil,
+	"cancelled": nil,
+	"no_show":   nil,
+}
+
+// check returns ErrInvalidStatus for unknown target statuses and
+// ErrIllegalTransition when to is not reachable from from.
+func (m statusMachine) check(from, to string) error {
+	if _, ok := m[to]; !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, to)
+	}
+	for _, next := range m[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: cannot change status from %s to %s", ErrIllegalTransition, from, to)
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStatusMachineCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		machine statusMachine
+		from    string
+		to      string
+		wantErr error
+	}{
+		{"order new to in_progress", orderStatuses, "new", "in_progress", nil},
+		{"order new to cancelled", orderStatuses, "new", "cancelled", nil},
+		{"order in_progress to closed", orderStatuses, "in_progress", "closed", nil},
+		{"order in_progress to cancelled", orderStatuses, "in_progress", "cancelled", nil},
+		{"order new skips to closed", orderStatuses, "new", "closed", ErrIllegalTransition},
+		{"order closed reopens", orderStatuses, "closed", "new", ErrIllegalTransition},
+		{"order cancelled to in_progress", orderStatuses, "cancelled", "in_progress", ErrIllegalTransition},
+		{"order same status", orderStatuses, "new", "new", ErrIllegalTransition},
+		{"order unknown target", orderStatuses, "new", "paid", ErrInvalidStatus},
+		{"order unknown source", orderStatuses, "draft", "new", ErrIllegalTransition},
+		{"reservation new to confirmed", reservationStatuses, "new", "confirmed", nil},
+		{"reservation confirmed to no_show", reservationStatuses, "confirmed", "no_show", nil},
+		{"reservation confirmed to completed", reservationStatuses, "confirmed", "completed", nil},
+		{"reservation new to no_show", reservationStatuses, "new", "no_show", ErrIllegalTransition},
+		{"reservation completed to cancelled", reservationStatuses, "completed", "cancelled", ErrIllegalTransition},
+		{"reservation unknown target", reservationStatuses, "new", "closed", ErrInvalidStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.machine.check(tt.from, tt.to)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("check(%q, %q) = %v, want nil", tt.from, tt.to, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("check(%q, %q) = %v, want %v", tt.from, tt.to, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStatusMachineTargetsDeclared(t *testing.T) {
+	for name, m := range map[string]statusMachine{"order": orderStatuses, "reservation": reservationStatuses} {
+		for from, next := range m {
+			for _, to := range next {
+				if _, ok := m[to]; !ok {
+					t.Errorf("%s: %s leads to undeclared status %s", name, from, to)
+				}
+			}
+		}
+	}
+}
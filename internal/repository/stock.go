@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"math"
+
+	"github.com/example/rms/internal/domain"
+)
+
+// Stock
+func (r *Repository) ListStock(ctx context.Context) ([]domain.ProductStock, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT p.id, p.name, p.unit, COALESCE(ps.quantity, 0), COALESCE(ps.updated_at, now())
+		FROM products p
+		LEFT JOIN product_stock ps ON ps.product_id = p.id
+		ORDER BY p.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.ProductStock
+	for rows.Next() {
+		var ps domain.ProductStock
+		if err := rows.Scan(&ps.ProductID, &ps.ProductName, &ps.Unit, &ps.Quantity, &ps.UpdatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, ps)
+	}
+	return res, rows.Err()
+}
+
+func (r *Repository) ListStockMovements(ctx context.Context, productID int64, limit int) ([]domain.StockMovement, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 200
+	}
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT id, product_id, movement_type, quantity_delta, quantity_after, COALESCE(reason,''), employee_id, order_id, created_at
+		FROM stock_movements WHERE product_id=$1 ORDER BY created_at DESC, id DESC LIMIT $2`, productID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.StockMovement
+	for rows.Next() {
+		var m domain.StockMovement
+		var employee, order sql.NullInt64
+		if err := rows.Scan(&m.ID, &m.ProductID, &m.MovementType, &m.QuantityDelta, &m.QuantityAfter, &m.Reason, &employee, &order, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		if employee.Valid {
+			val := employee.Int64
+			m.EmployeeID = &val
+		}
+		if order.Valid {
+			val := order.Int64
+			m.OrderID = &val
+		}
+		res = append(res, m)
+	}
+	return res, rows.Err()
+}
+
+// GetStockDiscrepancies lists products whose stock differs from the sum of their movements.
+func (r *Repository) GetStockDiscrepancies(ctx context.Context) ([]domain.StockDiscrepancy, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT p.id, p.name, COALESCE(ps.quantity, 0), COALESCE(m.total, 0)
+		FROM products p
+		LEFT JOIN product_stock ps ON ps.product_id = p.id
+		LEFT JOIN (
+			SELECT product_id, SUM(quantity_delta) AS total FROM stock_movements GROUP BY product_id
+		) m ON m.product_id = p.id
+		WHERE COALESCE(ps.quantity, 0) <> COALESCE(m.total, 0)
+		ORDER BY p.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.StockDiscrepancy
+	for rows.Next() {
+		var d domain.StockDiscrepancy
+		if err := rows.Scan(&d.ProductID, &d.ProductName, &d.StockQuantity, &d.LedgerQuantity); err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
+
+// RecordStockMovement applies a receipt, write-off or adjustment to product_stock
+// and appends it to the movement ledger. Receipts and write-offs carry a signed
+// QuantityDelta, adjustments carry the counted quantity in QuantityAfter.
+func (r *Repository) RecordStockMovement(ctx context.Context, m *domain.StockMovement) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return r.recordStockMovement(ctx, tx, m)
+	})
+}
+
+func (r *Repository) recordStockMovement(ctx context.Context, tx *sql.Tx, m *domain.StockMovement) error {
+	current, err := lockStock(ctx, tx, m.ProductID)
+	if err != nil {
+		return err
+	}
+	if m.MovementType == "adjustment" {
+		m.QuantityDelta = roundQuantity(m.QuantityAfter - current)
+	} else {
+		m.QuantityAfter = roundQuantity(current + m.QuantityDelta)
+	}
+	// Stock may already be negative under the allow_negative policy, so only
+	// movements that take goods away are checked.
+	if m.QuantityAfter < 0 && m.QuantityDelta < 0 {
+		return ErrInsufficientStock
+	}
+	return writeStockMovement(ctx, tx, m)
+}
+
+// writeStockMovement stores the already computed quantity and appends the movement to the ledger.
+// The product_stock row must be locked by the caller.
+func writeStockMovement(ctx context.Context, tx *sql.Tx, m *domain.StockMovement) error {
+	if _, err := tx.ExecContext(ctx, `UPDATE product_stock SET quantity=$1 WHERE product_id=$2`, m.QuantityAfter, m.ProductID); err != nil {
+		return err
+	}
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO stock_movements(product_id, movement_type, quantity_delta, quantity_after, reason, employee_id, order_id)
+		VALUES ($1,$2,$3,$4,NULLIF($5,''),$6,$7)
+		RETURNING id, created_at`,
+		m.ProductID, m.MovementType, m.QuantityDelta, m.QuantityAfter, m.Reason, m.EmployeeID, m.OrderID).
+		Scan(&m.ID, &m.CreatedAt)
+}
+
+// lockStock returns the current quantity of a product, creating an empty stock row
+// if needed, and holds a row lock until the transaction ends.
+func lockStock(ctx context.Context, tx *sql.Tx, productID int64) (float64, error) {
+	var id int64
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM products WHERE id=$1`, productID).Scan(&id); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO product_stock(product_id, quantity) VALUES ($1, 0) ON CONFLICT (product_id) DO NOTHING`, productID); err != nil {
+		return 0, err
+	}
+	var qty float64
+	err := tx.QueryRowContext(ctx, `SELECT quantity FROM product_stock WHERE product_id=$1 FOR UPDATE`, productID).Scan(&qty)
+	return qty, err
+}
+
+// roundQuantity trims float noise to the NUMERIC(12,3) precision used for stock.
+func roundQuantity(q float64) float64 {
+	return math.Round(q*1000) / 1000
+}
@@ -9,6 +9,23 @@ CREATE TABLE IF NOT EXISTS roles (
     is_system BOOLEAN NOT NULL DEFAULT FALSE
 );
 
+-- A grant is a permission such as 'orders:create', '<resource>:*' or '*'.
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id BIGINT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission TEXT NOT NULL,
+    PRIMARY KEY (role_id, permission)
+);
+
+-- The system admin role holds every permission on any install; the API cannot
+-- change system roles. BOOTSTRAP_ADMIN_* gives it its first login.
+INSERT INTO roles (name, description, is_system)
+VALUES ('admin', 'Администратор системы', TRUE)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT id, '*' FROM roles WHERE name = 'admin'
+ON CONFLICT DO NOTHING;
+
 CREATE TABLE IF NOT EXISTS employees (
     id BIGSERIAL PRIMARY KEY,
     full_name TEXT NOT NULL,
@@ -54,10 +71,22 @@ CREATE TABLE IF NOT EXISTS products (
 
 CREATE TABLE IF NOT EXISTS product_stock (
     product_id BIGINT PRIMARY KEY REFERENCES products(id) ON DELETE CASCADE,
-    quantity NUMERIC(12,3) NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    quantity NUMERIC(12,3) NOT NULL DEFAULT 0,
     updated_at TIMESTAMP NOT NULL DEFAULT now()
 );
 
+-- The ledger is never deleted: a product with movements cannot be deleted.
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    movement_type TEXT NOT NULL CHECK (movement_type IN ('receipt','write_off','adjustment','consumption','return')),
+    quantity_delta NUMERIC(12,3) NOT NULL,
+    quantity_after NUMERIC(12,3) NOT NULL,
+    reason TEXT,
+    employee_id BIGINT REFERENCES employees(id),
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
 CREATE TABLE IF NOT EXISTS dishes (
     id BIGSERIAL PRIMARY KEY,
     category_id BIGINT NOT NULL REFERENCES menu_categories(id),
@@ -83,7 +112,7 @@ CREATE TABLE IF NOT EXISTS reservations (
     table_id BIGINT NOT NULL REFERENCES restaurant_tables(id),
     reserved_from TIMESTAMP NOT NULL,
     reserved_to TIMESTAMP NOT NULL,
-    status TEXT NOT NULL CHECK (status IN ('new','confirmed','cancelled','completed')),
+    status TEXT NOT NULL CHECK (status IN ('new','confirmed','cancelled','completed','no_show')),
     created_at TIMESTAMP NOT NULL DEFAULT now(),
     CHECK (reserved_to > reserved_from)
 );
@@ -121,15 +150,46 @@ CREATE TABLE IF NOT EXISTS order_items (
     UNIQUE(order_id, dish_id)
 );
 
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
 CREATE TABLE IF NOT EXISTS payments (
     id BIGSERIAL PRIMARY KEY,
-    order_id BIGINT NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
     amount NUMERIC(10,2) NOT NULL CHECK (amount >= 0),
     method TEXT NOT NULL CHECK (method IN ('cash','card','online')),
     paid_at TIMESTAMP NOT NULL DEFAULT now(),
     status TEXT NOT NULL CHECK (status IN ('pending','paid','refunded'))
 );
 
+-- Login secrets are kept apart from employees so that audited employee rows
+-- never carry password or PIN hashes.
+CREATE TABLE IF NOT EXISTS employee_credentials (
+    employee_id BIGINT PRIMARY KEY REFERENCES employees(id) ON DELETE CASCADE,
+    pin_hash TEXT,
+    password_hash TEXT,
+    -- Consecutive wrong secrets; logins are refused until locked_until.
+    failed_logins INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- Refunds are a ledger against the original payment; a payment becomes
+-- 'refunded' once its refunds add up to the full amount.
+CREATE TABLE IF NOT EXISTS refunds (
+    id BIGSERIAL PRIMARY KEY,
+    payment_id BIGINT NOT NULL REFERENCES payments(id),
+    amount NUMERIC(10,2) NOT NULL CHECK (amount > 0),
+    reason TEXT NOT NULL,
+    approved_by BIGINT NOT NULL REFERENCES employees(id),
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
 CREATE TABLE IF NOT EXISTS audit_log (
     id BIGSERIAL PRIMARY KEY,
     table_name TEXT NOT NULL,
@@ -157,8 +217,65 @@ ALTER TABLE IF EXISTS reservations
     ADD COLUMN IF NOT EXISTS reserved_range tsrange
         GENERATED ALWAYS AS (tsrange(reserved_from, reserved_to, '[)')) STORED;
 
+-- Phone digits with the Russian trunk prefix 8 replaced by 7, so '+7 (999) 123-45-67'
+-- and '89991234567' are the same number for search.
+ALTER TABLE IF EXISTS customers
+    ADD COLUMN IF NOT EXISTS phone_normalized TEXT
+        GENERATED ALWAYS AS (regexp_replace(regexp_replace(phone, '\D', '', 'g'), '^8(\d{10})$', '7\1')) STORED;
+
+-- Negative stock is allowed by the STOCK_SHORTAGE_POLICY=allow_negative setting,
+-- non-negativity for the other policies is enforced by the application.
+ALTER TABLE IF EXISTS product_stock
+    DROP CONSTRAINT IF EXISTS product_stock_quantity_check;
+
+-- An order can be paid with several payments (split bills, cash plus card).
+ALTER TABLE IF EXISTS payments
+    DROP CONSTRAINT IF EXISTS payments_order_id_key;
+
+ALTER TABLE IF EXISTS orders
+    ADD COLUMN IF NOT EXISTS stock_deducted BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Pricing: line and order discounts, the customer's VIP discount and the service charge rate,
+-- the last two fixed when the order was created.
+ALTER TABLE IF EXISTS orders
+    ADD COLUMN IF NOT EXISTS discount_percent NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (discount_percent BETWEEN 0 AND 100),
+    ADD COLUMN IF NOT EXISTS service_charge_percent NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (service_charge_percent BETWEEN 0 AND 100),
+    ADD COLUMN IF NOT EXISTS loyalty_discount_percent NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (loyalty_discount_percent BETWEEN 0 AND 100);
+
+ALTER TABLE IF EXISTS order_items
+    ADD COLUMN IF NOT EXISTS price_override_reason TEXT,
+    ADD COLUMN IF NOT EXISTS price_override_by BIGINT REFERENCES employees(id),
+    ADD COLUMN IF NOT EXISTS discount_percent NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (discount_percent BETWEEN 0 AND 100);
+
+-- Failed import rows stay open until a retry succeeds or they are dismissed.
+ALTER TABLE IF EXISTS import_errors
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','resolved','dismissed')),
+    ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS resolved_by BIGINT REFERENCES employees(id) ON DELETE SET NULL;
+
+-- The HTTP request that made the change; see fn_audit.
+ALTER TABLE IF EXISTS audit_log
+    ADD COLUMN IF NOT EXISTS request_id TEXT;
+
+ALTER TABLE IF EXISTS stock_movements
+    ADD COLUMN IF NOT EXISTS order_id BIGINT REFERENCES orders(id) ON DELETE SET NULL;
+
+ALTER TABLE IF EXISTS shifts
+    ADD COLUMN IF NOT EXISTS revenue_discrepancy NUMERIC(12,2)
+        GENERATED ALWAYS AS (actual_revenue - expected_revenue) STORED;
+
+-- Cancelled and no-show reservations release their time slot.
 DO $$
 BEGIN
+    IF EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'reservations_no_overlap'
+          AND conrelid = 'reservations'::regclass
+          AND pg_get_constraintdef(oid) NOT LIKE '%WHERE%'
+    ) THEN
+        ALTER TABLE reservations DROP CONSTRAINT reservations_no_overlap;
+    END IF;
+
     IF NOT EXISTS (
         SELECT 1 FROM pg_constraint
         WHERE conname = 'reservations_no_overlap'
@@ -169,7 +286,7 @@ BEGIN
         EXCLUDE USING gist (
             table_id WITH =,
             reserved_range WITH &&
-        );
+        ) WHERE (status NOT IN ('cancelled','no_show'));
     END IF;
 END;
 $$;
@@ -192,8 +309,12 @@ $$;
 CREATE INDEX IF NOT EXISTS idx_employees_role_id ON employees(role_id);
 CREATE INDEX IF NOT EXISTS idx_customers_phone ON customers(phone);
 CREATE INDEX IF NOT EXISTS idx_customers_created_at ON customers(created_at);
+CREATE INDEX IF NOT EXISTS idx_customers_phone_normalized ON customers(phone_normalized text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_customers_created_at_id ON customers(created_at DESC, id DESC);
 CREATE INDEX IF NOT EXISTS idx_tables_active ON restaurant_tables(is_active);
 CREATE INDEX IF NOT EXISTS idx_products_available ON products(is_available);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product_id ON stock_movements(product_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_order_id ON stock_movements(order_id);
 CREATE INDEX IF NOT EXISTS idx_dishes_category_id ON dishes(category_id);
 CREATE INDEX IF NOT EXISTS idx_dishes_active ON dishes(is_active);
 CREATE INDEX IF NOT EXISTS idx_dish_ingredients_dish_id ON dish_ingredients(dish_id);
@@ -214,22 +335,38 @@ CREATE INDEX IF NOT EXISTS idx_orders_shift_id ON orders(shift_id);
 CREATE INDEX IF NOT EXISTS idx_orders_reservation_id ON orders(reservation_id);
 CREATE INDEX IF NOT EXISTS idx_orders_status ON orders(status);
 CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders(created_at);
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order_id ON order_status_history(order_id, changed_at);
 CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items(order_id);
 CREATE INDEX IF NOT EXISTS idx_order_items_dish_id ON order_items(dish_id);
 CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments(order_id);
 CREATE INDEX IF NOT EXISTS idx_payments_status ON payments(status);
 CREATE INDEX IF NOT EXISTS idx_payments_paid_at ON payments(paid_at);
+CREATE INDEX IF NOT EXISTS idx_refunds_payment_id ON refunds(payment_id);
 CREATE INDEX IF NOT EXISTS idx_audit_log_table_name ON audit_log(table_name, record_id);
 CREATE INDEX IF NOT EXISTS idx_audit_log_changed_at ON audit_log(changed_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_changed_by ON audit_log(changed_by);
+CREATE INDEX IF NOT EXISTS idx_audit_log_request_id ON audit_log(request_id) WHERE request_id IS NOT NULL;
+-- Order and payment history look up item, payment and refund rows by their parent.
+CREATE INDEX IF NOT EXISTS idx_audit_log_order_id ON audit_log((COALESCE(new_data, old_data)->>'order_id'))
+    WHERE table_name IN ('order_items','payments');
+CREATE INDEX IF NOT EXISTS idx_audit_log_payment_id ON audit_log((COALESCE(new_data, old_data)->>'payment_id'))
+    WHERE table_name = 'refunds';
 CREATE INDEX IF NOT EXISTS idx_import_errors_created_at ON import_errors(created_at);
 CREATE INDEX IF NOT EXISTS idx_import_errors_entity ON import_errors(entity);
+CREATE INDEX IF NOT EXISTS idx_import_errors_open ON import_errors(id) WHERE status = 'open';
 
 -- Functions and triggers
 
+-- The application stores the acting employee id and request id in the
+-- transaction-local settings rms.actor_id and rms.request_id; changes made
+-- outside the API fall back to the database user.
 CREATE OR REPLACE FUNCTION fn_audit() RETURNS TRIGGER AS $$
 BEGIN
-    INSERT INTO audit_log(table_name, record_id, operation, changed_by, old_data, new_data)
-    VALUES (TG_TABLE_NAME, COALESCE(NEW.id, OLD.id), TG_OP, CURRENT_USER, to_jsonb(OLD), to_jsonb(NEW));
+    INSERT INTO audit_log(table_name, record_id, operation, changed_by, request_id, old_data, new_data)
+    VALUES (TG_TABLE_NAME, COALESCE(NEW.id, OLD.id), TG_OP,
+            COALESCE(NULLIF(current_setting('rms.actor_id', true), ''), CURRENT_USER),
+            NULLIF(current_setting('rms.request_id', true), ''),
+            to_jsonb(OLD), to_jsonb(NEW));
     RETURN COALESCE(NEW, OLD);
 END;
 $$ LANGUAGE plpgsql;
@@ -273,7 +410,7 @@ DO $$
 DECLARE
     tbl TEXT;
 BEGIN
-    FOREACH tbl IN ARRAY ARRAY['customers','employees','products','dishes','reservations','shifts','orders','order_items','payments'] LOOP
+    FOREACH tbl IN ARRAY ARRAY['customers','employees','products','dishes','dish_ingredients','reservations','shifts','orders','order_items','payments','refunds'] LOOP
         EXECUTE format('DROP TRIGGER IF EXISTS trg_audit_%s ON %s;', tbl, tbl);
         EXECUTE format('CREATE TRIGGER trg_audit_%s AFTER INSERT OR UPDATE OR DELETE ON %s FOR EACH ROW EXECUTE FUNCTION fn_audit();', tbl, tbl);
     END LOOP;
@@ -294,17 +431,33 @@ LEFT JOIN dish_ingredients di ON di.dish_id = d.id
 LEFT JOIN products p ON p.id = di.product_id
 GROUP BY d.id, d.name, d.price, d.is_active;
 
+-- Money actually kept from each payment: paid (or later refunded) amount minus its refunds.
+CREATE OR REPLACE VIEW view_payment_net AS
+SELECT
+    p.id AS payment_id,
+    p.order_id,
+    CASE WHEN p.status IN ('paid','refunded') THEN p.amount ELSE 0 END AS gross_amount,
+    COALESCE(r.refunded, 0) AS refunded_amount,
+    CASE WHEN p.status IN ('paid','refunded') THEN p.amount ELSE 0 END - COALESCE(r.refunded, 0) AS net_amount
+FROM payments p
+LEFT JOIN (
+    SELECT payment_id, SUM(amount) AS refunded
+    FROM refunds
+    GROUP BY payment_id
+) r ON r.payment_id = p.id;
+
 CREATE OR REPLACE VIEW view_shift_revenue AS
 SELECT
     s.id AS shift_id,
     s.opened_at,
     s.closed_at,
     COUNT(DISTINCT o.id) AS orders_count,
-    COALESCE(SUM(CASE WHEN pay.status = 'paid' THEN pay.amount ELSE 0 END), 0) AS total_revenue,
-    CASE WHEN COUNT(DISTINCT o.id) = 0 THEN NULL ELSE ROUND(COALESCE(SUM(CASE WHEN pay.status = 'paid' THEN pay.amount ELSE 0 END), 0) / COUNT(DISTINCT o.id), 2) END AS avg_check
+    COALESCE(SUM(pay.net_amount), 0) AS total_revenue,
+    CASE WHEN COUNT(DISTINCT o.id) = 0 THEN NULL ELSE ROUND(COALESCE(SUM(pay.net_amount), 0) / COUNT(DISTINCT o.id), 2) END AS avg_check,
+    COALESCE(SUM(pay.refunded_amount), 0) AS total_refunds
 FROM shifts s
 LEFT JOIN orders o ON o.shift_id = s.id
-LEFT JOIN payments pay ON pay.order_id = o.id
+LEFT JOIN view_payment_net pay ON pay.order_id = o.id
 GROUP BY s.id, s.opened_at, s.closed_at;
 
 CREATE OR REPLACE VIEW view_waiter_performance AS
@@ -312,11 +465,11 @@ SELECT
     e.id AS waiter_id,
     e.full_name,
     COUNT(DISTINCT o.id) AS orders_count,
-    COALESCE(SUM(CASE WHEN p.status = 'paid' THEN p.amount ELSE 0 END), 0) AS total_revenue,
-    CASE WHEN COUNT(DISTINCT o.id) = 0 THEN NULL ELSE ROUND(COALESCE(SUM(CASE WHEN p.status = 'paid' THEN p.amount ELSE 0 END), 0) / COUNT(DISTINCT o.id), 2) END AS avg_check
+    COALESCE(SUM(p.net_amount), 0) AS total_revenue,
+    CASE WHEN COUNT(DISTINCT o.id) = 0 THEN NULL ELSE ROUND(COALESCE(SUM(p.net_amount), 0) / COUNT(DISTINCT o.id), 2) END AS avg_check
 FROM employees e
 LEFT JOIN orders o ON o.waiter_id = e.id
-LEFT JOIN payments p ON p.order_id = o.id
+LEFT JOIN view_payment_net p ON p.order_id = o.id
 WHERE e.is_active = TRUE
 GROUP BY e.id, e.full_name;
 
@@ -337,11 +490,10 @@ CREATE OR REPLACE FUNCTION get_customer_total_spent(p_customer_id BIGINT) RETURN
 DECLARE
     total NUMERIC;
 BEGIN
-    SELECT COALESCE(SUM(p.amount), 0) INTO total
-    FROM payments p
+    SELECT COALESCE(SUM(p.net_amount), 0) INTO total
+    FROM view_payment_net p
     JOIN orders o ON o.id = p.order_id
-    WHERE o.customer_id = p_customer_id
-      AND p.status = 'paid';
+    WHERE o.customer_id = p_customer_id;
     RETURN total;
 END;
 $$ LANGUAGE plpgsql STABLE;
@@ -350,11 +502,10 @@ CREATE OR REPLACE FUNCTION get_shift_revenue(p_shift_id BIGINT) RETURNS NUMERIC
 DECLARE
     total NUMERIC;
 BEGIN
-    SELECT COALESCE(SUM(p.amount), 0) INTO total
-    FROM payments p
+    SELECT COALESCE(SUM(p.net_amount), 0) INTO total
+    FROM view_payment_net p
     JOIN orders o ON o.id = p.order_id
-    WHERE o.shift_id = p_shift_id
-      AND p.status = 'paid';
+    WHERE o.shift_id = p_shift_id;
     RETURN total;
 END;
 $$ LANGUAGE plpgsql STABLE;
@@ -376,11 +527,11 @@ BEGIN
         s.opened_at,
         s.closed_at,
         COUNT(DISTINCT o.id) AS orders_count,
-        COALESCE(SUM(CASE WHEN pay.status = 'paid' THEN pay.amount ELSE 0 END), 0) AS total_revenue,
-        CASE WHEN COUNT(DISTINCT o.id) = 0 THEN NULL ELSE ROUND(COALESCE(SUM(CASE WHEN pay.status = 'paid' THEN pay.amount ELSE 0 END), 0) / COUNT(DISTINCT o.id), 2) END AS avg_check
+        COALESCE(SUM(pay.net_amount), 0) AS total_revenue,
+        CASE WHEN COUNT(DISTINCT o.id) = 0 THEN NULL ELSE ROUND(COALESCE(SUM(pay.net_amount), 0) / COUNT(DISTINCT o.id), 2) END AS avg_check
     FROM shifts s
     LEFT JOIN orders o ON o.shift_id = s.id
-    LEFT JOIN payments pay ON pay.order_id = o.id
+    LEFT JOIN view_payment_net pay ON pay.order_id = o.id
     WHERE s.opened_at::date BETWEEN p_from AND p_to
     GROUP BY s.id, s.opened_at, s.closed_at;
 END;
@@ -5,15 +5,65 @@ SELECT setseed(0.42);
 -----------
 -- ROLES --
 -----------
+-- Системная роль admin с правом '*' создаётся в schema.sql
 INSERT INTO roles (name, description, is_system)
 VALUES 
-    ('admin',      'Администратор системы', TRUE),
     ('manager',    'Управляющий сменой',    FALSE),
     ('waiter',     'Официант',              FALSE),
     ('chef',       'Повар',                 FALSE),
     ('bartender',  'Бармен',                FALSE)
 ON CONFLICT (name) DO NOTHING;
 
+-- Права ролей: '*' — все действия, 'orders:*' — все действия с заказами
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM (VALUES
+    ('manager',   'customers:*'),
+    ('manager',   'employees:read'),
+    ('manager',   'roles:read'),
+    ('manager',   'tables:*'),
+    ('manager',   'menu:*'),
+    ('manager',   'dishes:*'),
+    ('manager',   'products:*'),
+    ('manager',   'stock:*'),
+    ('manager',   'reservations:*'),
+    ('manager',   'orders:*'),
+    ('manager',   'payments:*'),
+    ('manager',   'shifts:*'),
+    ('manager',   'reports:read'),
+    ('manager',   'import:*'),
+    ('manager',   'audit:read'),
+    ('waiter',    'customers:read'),
+    ('waiter',    'customers:create'),
+    ('waiter',    'customers:update'),
+    ('waiter',    'tables:read'),
+    ('waiter',    'menu:read'),
+    ('waiter',    'dishes:read'),
+    ('waiter',    'reservations:*'),
+    ('waiter',    'orders:read'),
+    ('waiter',    'orders:create'),
+    ('waiter',    'orders:update'),
+    ('waiter',    'payments:read'),
+    ('waiter',    'payments:create'),
+    ('waiter',    'shifts:read'),
+    ('chef',      'menu:read'),
+    ('chef',      'dishes:*'),
+    ('chef',      'products:read'),
+    ('chef',      'stock:*'),
+    ('chef',      'orders:read'),
+    ('chef',      'orders:update'),
+    ('bartender', 'menu:read'),
+    ('bartender', 'dishes:read'),
+    ('bartender', 'products:read'),
+    ('bartender', 'stock:read'),
+    ('bartender', 'orders:read'),
+    ('bartender', 'orders:update'),
+    ('bartender', 'payments:read'),
+    ('bartender', 'payments:create')
+) AS p(role, permission)
+JOIN roles r ON r.name = p.role
+ON CONFLICT DO NOTHING;
+
 ---------------
 -- EMPLOYEES --
 ---------------
@@ -53,6 +103,14 @@ CROSS JOIN LATERAL (
 ) ln
 ON CONFLICT (phone) DO NOTHING;
 
+-- Учётные данные для входа: PIN 1234 (касса) и пароль password123 (бэк-офис)
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+INSERT INTO employee_credentials (employee_id, pin_hash, password_hash)
+SELECT id, crypt('1234', gen_salt('bf', 10)), crypt('password123', gen_salt('bf', 10))
+FROM employees
+ON CONFLICT (employee_id) DO NOTHING;
+
 -----------------------
 -- RESTAURANT TABLES --
 -----------------------
@@ -167,6 +225,12 @@ SET quantity   = EXCLUDED.quantity,
 
 -- Триггер автоматически обновит products.is_available в зависимости от quantity
 
+-- Начальные остатки фиксируем в журнале движений, чтобы остатки сходились с историей
+INSERT INTO stock_movements (product_id, movement_type, quantity_delta, quantity_after, reason, created_at)
+SELECT ps.product_id, 'adjustment', ps.quantity, ps.quantity, 'Начальный остаток', ps.updated_at
+FROM product_stock ps
+WHERE NOT EXISTS (SELECT 1 FROM stock_movements sm WHERE sm.product_id = ps.product_id);
+
 ---------------
 -- CUSTOMERS --
 ---------------
@@ -370,6 +434,16 @@ SET reservation_id = r.id
 FROM ordered_res r
 WHERE o.id = r.rn;
 
+-- История статусов: каждый заказ создаётся в 'new' и проходит допустимые переходы
+INSERT INTO order_status_history (order_id, from_status, to_status, changed_at)
+SELECT id, NULL, 'new', created_at FROM orders
+UNION ALL
+SELECT id, 'new', 'in_progress', created_at + interval '5 minutes' FROM orders WHERE status IN ('in_progress','closed')
+UNION ALL
+SELECT id, 'in_progress', 'closed', created_at + interval '60 minutes' FROM orders WHERE status = 'closed'
+UNION ALL
+SELECT id, 'new', 'cancelled', created_at + interval '10 minutes' FROM orders WHERE status = 'cancelled';
+
 -----------------
 -- ORDER ITEMS --
 -----------------
@@ -407,5 +481,19 @@ SELECT
         ELSE 'paid'
     END AS status,
     o.created_at + interval '30 minutes' AS paid_at
-FROM orders o
-ON CONFLICT (order_id) DO NOTHING;
\ No newline at end of file
+FROM orders o;
+
+-------------
+-- REFUNDS --
+-------------
+-- Возвраты по отменённым заказам: полная сумма оплаты, подтверждает менеджер
+INSERT INTO refunds (payment_id, amount, reason, approved_by, created_at)
+SELECT
+    p.id,
+    p.amount,
+    'Отмена заказа',
+    (SELECT id FROM employees WHERE role_id = (SELECT id FROM roles WHERE name='manager') ORDER BY random() LIMIT 1),
+    p.paid_at + interval '10 minutes'
+FROM payments p
+WHERE p.status = 'refunded'
+  AND p.amount > 0;